@@ -8,6 +8,6 @@ import (
 
 func main() {
 	if err := commands.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(commands.ExitCode(err))
 	}
 }
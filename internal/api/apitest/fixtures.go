@@ -0,0 +1,75 @@
+package apitest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/api/fake"
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// Fixture execution IDs
+const (
+	// ProcessesExecutionID is a completed "Running Processes" run with one error result
+	ProcessesExecutionID = "e1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"
+
+	// ProcessesBaselineID is an earlier "Running Processes" run to compare against
+	ProcessesBaselineID = "b0a1b2c3-d4e5-4f6a-8b7c-8d9e0f1a2b3c"
+
+	// SoftwareExecutionID is a completed "Installed Software" run with nested results
+	SoftwareExecutionID = "5f6e7d8c-9b0a-4c1d-8e2f-3a4b5c6d7e8f"
+)
+
+// Fixtures returns a fake backend seeded with sample agents, scripts and executions
+func Fixtures() (*fake.Backend, error) {
+	b := fake.New()
+
+	if err := loadFixture("agents.json", &b.Agents); err != nil {
+		return nil, err
+	}
+	if err := loadFixture("scripts.json", &b.Scripts); err != nil {
+		return nil, err
+	}
+
+	var executions []executionFixture
+	if err := loadFixture("executions.json", &executions); err != nil {
+		return nil, err
+	}
+	for _, e := range executions {
+		b.AddExecution(e.Execution, e.Status, e.Results)
+	}
+
+	return b, nil
+}
+
+// MustFixtures is like Fixtures but panics on error
+func MustFixtures() *fake.Backend {
+	b, err := Fixtures()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// loadFixture decodes an embedded fixture file into v
+func loadFixture(name string, v interface{}) error {
+	data, err := fixtureFiles.ReadFile("fixtures/" + name)
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+// executionFixture is the on-disk form of a fake execution
+type executionFixture struct {
+	Execution api.Execution         `json:"execution"`
+	Status    api.ExecutionStatus   `json:"status"`
+	Results   []api.ExecutionResult `json:"results"`
+}
@@ -0,0 +1,157 @@
+// Package apitest provides an httptest server speaking the Binmave API and
+// sample fixtures, for testing code that uses api.Client end to end.
+package apitest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+)
+
+// Token is the bearer token accepted by the stub server
+const Token = "apitest-token"
+
+// Server is an httptest server serving the Binmave API from a Backend
+type Server struct {
+	*httptest.Server
+	Backend api.Backend
+}
+
+// NewServer starts a stub server backed by b. Callers must Close it.
+func NewServer(b api.Backend) *Server {
+	s := &Server{Backend: b}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// APIClient returns an api.Client authenticated against the stub server
+func (s *Server) APIClient(opts ...api.Option) *api.Client {
+	client, err := api.NewClientWithToken(s.URL, &auth.TokenInfo{
+		AccessToken: Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// serveHTTP routes API paths to the backend
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized", "missing or invalid bearer token")
+		return
+	}
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/agents/filterable":
+		agents, err := s.Backend.ListAgents(ctx)
+		writeResult(w, api.LoadResult{Data: agents, TotalCount: len(agents)}, err)
+
+	case r.Method == "GET" && r.URL.Path == "/api/agents/stats":
+		stats, err := s.Backend.GetAgentStats(ctx)
+		writeResult(w, stats, err)
+
+	case r.Method == "GET" && r.URL.Path == "/api/scripts/filterable":
+		scripts, err := s.Backend.ListScripts(ctx)
+		writeResult(w, api.LoadResult{Data: scripts, TotalCount: len(scripts)}, err)
+
+	case r.Method == "GET" && r.URL.Path == "/api/scripts/executions/recent":
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		executions, err := s.Backend.ListRecentExecutions(ctx, limit)
+		writeResult(w, executions, err)
+
+	case r.Method == "GET" && len(parts) >= 4 && parts[1] == "scripts" && parts[2] == "executions":
+		s.serveExecution(w, r, parts[3], parts[4:])
+
+	case len(parts) == 3 && parts[1] == "scripts" && r.Method == "GET":
+		id, err := strconv.Atoi(parts[2])
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "Bad Request", "invalid script ID")
+			return
+		}
+		script, err := s.Backend.GetScript(ctx, id)
+		writeResult(w, script, err)
+
+	case len(parts) == 4 && parts[1] == "scripts" && parts[3] == "execute" && r.Method == "POST":
+		id, err := strconv.Atoi(parts[2])
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "Bad Request", "invalid script ID")
+			return
+		}
+		var req api.ExecuteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeProblem(w, http.StatusBadRequest, "Bad Request", err.Error())
+			return
+		}
+		req.IdempotencyKey = r.Header.Get(api.IdempotencyKeyHeader)
+		resp, err := s.Backend.ExecuteScript(ctx, id, req)
+		writeResult(w, resp, err)
+
+	default:
+		writeProblem(w, http.StatusNotFound, "Not Found", "no route for "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// serveExecution handles /api/scripts/executions/{id}[/status|/results]
+func (s *Server) serveExecution(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	ctx := r.Context()
+
+	switch {
+	case len(rest) == 0:
+		execution, err := s.Backend.GetExecution(ctx, id)
+		writeResult(w, execution, err)
+
+	case len(rest) == 1 && rest[0] == "status":
+		status, err := s.Backend.GetExecutionStatus(ctx, id)
+		writeResult(w, status, err)
+
+	case len(rest) == 1 && rest[0] == "results":
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		results, err := s.Backend.GetExecutionResults(ctx, id, page, pageSize)
+		writeResult(w, results, err)
+
+	default:
+		writeProblem(w, http.StatusNotFound, "Not Found", "no route for "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// writeResult writes v as JSON, or err as problem details
+func writeResult(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) {
+			writeProblem(w, apiErr.StatusCode, apiErr.Title, apiErr.Detail)
+			return
+		}
+		writeProblem(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeProblem writes an RFC 7807 problem details response
+func writeProblem(w http.ResponseWriter, status int, title, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"title":  title,
+		"status": status,
+		"detail": detail,
+	})
+}
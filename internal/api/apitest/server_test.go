@@ -0,0 +1,88 @@
+package apitest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/api/apitest"
+)
+
+func TestClientAgainstStubServer(t *testing.T) {
+	backend := apitest.MustFixtures()
+	server := apitest.NewServer(backend)
+	defer server.Close()
+
+	client := server.APIClient()
+	ctx := context.Background()
+
+	t.Run("ListAgents", func(t *testing.T) {
+		agents, err := client.ListAgents(ctx)
+		if err != nil {
+			t.Fatalf("ListAgents: %v", err)
+		}
+		if len(agents) != len(backend.Agents) {
+			t.Fatalf("got %d agents, want %d", len(agents), len(backend.Agents))
+		}
+		for i, agent := range agents {
+			if agent.AgentID != backend.Agents[i].AgentID || agent.MachineName != backend.Agents[i].MachineName {
+				t.Errorf("agent %d = %s/%s, want %s/%s", i, agent.AgentID, agent.MachineName,
+					backend.Agents[i].AgentID, backend.Agents[i].MachineName)
+			}
+		}
+	})
+
+	t.Run("ExecuteScript", func(t *testing.T) {
+		req := api.ExecuteRequest{
+			FilterGridString: "machineName=myserver",
+			Inputs:           []api.ScriptInput{{Key: "path", Value: `C:\Temp`}},
+			IdempotencyKey:   "3f1c2a9e-0b7d-4c55-9a61-2d8e4f7b1a03",
+		}
+		resp, err := client.ExecuteScript(ctx, 42, req)
+		if err != nil {
+			t.Fatalf("ExecuteScript: %v", err)
+		}
+		if resp.ExecutionID == "" || resp.ScriptID != 42 || resp.ScriptName != "Running Processes" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+
+		if len(backend.Submissions) != 1 {
+			t.Fatalf("got %d submissions, want 1", len(backend.Submissions))
+		}
+		got := backend.Submissions[0].Request
+		if got.FilterGridString != req.FilterGridString {
+			t.Errorf("filter = %q, want %q", got.FilterGridString, req.FilterGridString)
+		}
+		if got.IdempotencyKey != req.IdempotencyKey {
+			t.Errorf("idempotency key = %q, want %q", got.IdempotencyKey, req.IdempotencyKey)
+		}
+		if len(got.Inputs) != 1 || got.Inputs[0] != req.Inputs[0] {
+			t.Errorf("inputs = %+v, want %+v", got.Inputs, req.Inputs)
+		}
+	})
+
+	t.Run("ExecuteScriptUnknown", func(t *testing.T) {
+		_, err := client.ExecuteScript(ctx, 999, api.ExecuteRequest{})
+		if !api.IsNotFound(err) {
+			t.Fatalf("got %v, want a not found error", err)
+		}
+	})
+
+	t.Run("GetAllExecutionResults", func(t *testing.T) {
+		want := backend.Executions[apitest.ProcessesExecutionID].Results
+
+		// A page size below the result count makes every page a request
+		results, err := api.GetAllExecutionResults(ctx, client, apitest.ProcessesExecutionID, api.WithPageSize(1))
+		if err != nil {
+			t.Fatalf("GetAllExecutionResults: %v", err)
+		}
+		if len(results) != len(want) {
+			t.Fatalf("got %d results, want %d", len(results), len(want))
+		}
+		for i, result := range results {
+			if result.ResultID != want[i].ResultID || result.AgentID != want[i].AgentID || result.AnswerJSON != want[i].AnswerJSON {
+				t.Errorf("result %d = %d/%s, want %d/%s", i, result.ResultID, result.AgentID, want[i].ResultID, want[i].AgentID)
+			}
+		}
+	})
+}
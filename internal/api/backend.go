@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+)
+
+// Backend is the set of Binmave API operations used by the CLI.
+// *Client implements it against a server; internal/api/fake provides an
+// in-memory implementation for tests.
+type Backend interface {
+	ListAgents(ctx context.Context) ([]Agent, error)
+	GetAgentStats(ctx context.Context) (*AgentStats, error)
+
+	ListScripts(ctx context.Context) ([]Script, error)
+	GetScript(ctx context.Context, id int) (*Script, error)
+	ExecuteScript(ctx context.Context, scriptID int, req ExecuteRequest) (*ExecuteResponse, error)
+
+	ListRecentExecutions(ctx context.Context, limit int) ([]ExecutionListItem, error)
+	GetExecution(ctx context.Context, id string) (*Execution, error)
+	GetExecutionStatus(ctx context.Context, id string) (*ExecutionStatus, error)
+	GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*ExecutionResultsPage, error)
+}
+
+var _ Backend = (*Client)(nil)
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interaction is one recorded request/response pair, stored as a cassette file
+type Interaction struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recordedAt"`
+	DurationMs int64            `json:"durationMs"`
+}
+
+// RecordedRequest is the recorded form of a request. URL holds only the path
+// and query so cassettes replay against any server.
+type RecordedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Text   string          `json:"text,omitempty"` // Body when it is not JSON
+}
+
+// RecordedResponse is the recorded form of a response
+type RecordedResponse struct {
+	StatusCode int             `json:"statusCode"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	Text       string          `json:"text,omitempty"` // Body when it is not JSON
+}
+
+// unsafeFileChars matches characters not allowed in cassette file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// recordingTransport writes every request/response through it to a cassette directory
+type recordingTransport struct {
+	base http.RoundTripper
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecordingTransport creates the directory and continues numbering after
+// any interactions already recorded there
+func newRecordingTransport(base http.RoundTripper, dir string) (*recordingTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingTransport{base: base, dir: dir, seq: len(existing)}, nil
+}
+
+// RoundTrip performs the request and records it with secrets redacted
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: redactHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+		},
+		RecordedAt: start.UTC(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	interaction.Request.Body, interaction.Request.Text = encodeBody(reqBody)
+	interaction.Response.Body, interaction.Response.Text = encodeBody(respBody)
+
+	if err := t.write(req, &interaction); err != nil {
+		return nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+
+	return resp, nil
+}
+
+// write stores an interaction as the next numbered cassette file
+func (t *recordingTransport) write(req *http.Request, interaction *Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.seq++
+	seq := t.seq
+	t.mu.Unlock()
+
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(req.URL.Path, "_"), "_")
+	path := filepath.Join(t.dir, fmt.Sprintf("%04d-%s-%s.json", seq, req.Method, name))
+	return os.WriteFile(path, data, 0600)
+}
+
+// encodeBody returns a redacted body as JSON when possible, or as text
+func encodeBody(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+	body = redactBody(body)
+	if json.Valid(body) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, body); err == nil {
+			return compact.Bytes(), ""
+		}
+	}
+	return nil, string(body)
+}
+
+// replayTransport serves responses from a cassette directory without network access.
+// Identical requests are answered in recorded order; once exhausted, the last
+// recorded response is repeated (e.g. for status polling).
+type replayTransport struct {
+	mu           sync.Mutex
+	interactions map[string][]*Interaction
+	served       map[string]int
+}
+
+// newReplayTransport loads every interaction in dir
+func newReplayTransport(dir string) (*replayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded interactions found in %s", dir)
+	}
+	sort.Strings(files)
+
+	t := &replayTransport{
+		interactions: make(map[string][]*Interaction),
+		served:       make(map[string]int),
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		key := replayKey(interaction.Request.Method, interaction.Request.URL)
+		t.interactions[key] = append(t.interactions[key], &interaction)
+	}
+
+	return t, nil
+}
+
+// RoundTrip answers the request from the cassette
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := replayKey(req.Method, req.URL.RequestURI())
+
+	t.mu.Lock()
+	recorded := t.interactions[key]
+	idx := t.served[key]
+	if idx < len(recorded) {
+		t.served[key]++
+	}
+	t.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("replay: no recorded response for %s %s", req.Method, req.URL.RequestURI())
+	}
+	if idx >= len(recorded) {
+		idx = len(recorded) - 1
+	}
+	interaction := recorded[idx]
+
+	body := []byte(interaction.Response.Text)
+	if len(interaction.Response.Body) > 0 {
+		body = interaction.Response.Body
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func replayKey(method, uri string) string {
+	return method + " " + uri
+}
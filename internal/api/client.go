@@ -6,49 +6,254 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/Binmave/binmave-cli/internal/auth"
 	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/httpclient"
 )
 
 // Client is the API client for the Binmave backend
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	token      *auth.TokenInfo
+	retry      RetryPolicy
+	logger     *slog.Logger
+
+	// tokenMu guards token, which concurrent page fetches share;
+	// refreshMu lets only one of them refresh it at a time
+	tokenMu   sync.Mutex
+	refreshMu sync.Mutex
+	token     *auth.TokenInfo
 }
 
-// NewClient creates a new API client
-func NewClient() (*Client, error) {
+// Option configures optional Client behaviour
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	recordDir string
+	replayDir string
+	logger    *slog.Logger
+	bodyLimit int
+}
+
+// WithRecording records every request/response to cassette files in dir,
+// with authorization headers and tokens redacted
+func WithRecording(dir string) Option {
+	return func(o *clientOptions) {
+		o.recordDir = dir
+	}
+}
+
+// WithReplay serves responses from cassette files in dir instead of the network.
+// No credentials are needed in replay mode.
+func WithReplay(dir string) Option {
+	return func(o *clientOptions) {
+		o.replayDir = dir
+	}
+}
+
+// NewClient creates a new API client for the configured server using the stored credentials
+func NewClient(opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.replayDir != "" {
+		return newClient(config.GetServer(), nil, o)
+	}
+
 	token, err := auth.GetValidToken()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 
 	if token == nil {
-		return nil, fmt.Errorf("not logged in. Run 'binmave login' first")
+		return nil, ErrNotLoggedIn
+	}
+
+	return newClient(config.GetServer(), token, o)
+}
+
+// NewClientWithToken creates an API client for baseURL authenticating with token.
+// It does not read the configuration or stored credentials.
+func NewClientWithToken(baseURL string, token *auth.TokenInfo, opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newClient(baseURL, token, o)
+}
+
+// newClient builds a client, installing the recording, replay and logging
+// transports if requested
+func newClient(baseURL string, token *auth.TokenInfo, o clientOptions) (*Client, error) {
+	transport, err := httpclient.Transport()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case o.replayDir != "":
+		replay, err := newReplayTransport(o.replayDir)
+		if err != nil {
+			return nil, err
+		}
+		transport = replay
+		if token == nil {
+			// Recorded requests carry a redacted Authorization header anyway
+			token = &auth.TokenInfo{AccessToken: "replay", TokenType: "Bearer", ExpiresAt: time.Now().Add(24 * time.Hour)}
+		}
+	case o.recordDir != "":
+		recorder, err := newRecordingTransport(transport, o.recordDir)
+		if err != nil {
+			return nil, err
+		}
+		transport = recorder
+	}
+
+	logger := discardLogger
+	if o.logger != nil {
+		logger = o.logger
+		transport = &loggingTransport{next: transport, logger: logger, bodyLimit: o.bodyLimit}
 	}
 
 	return &Client{
-		baseURL: config.GetServer(),
+		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
-		token: token,
+		token:  token,
+		retry:  retryPolicyFromConfig(),
+		logger: logger,
 	}, nil
 }
 
 // doRequest performs an authenticated HTTP request
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	var bodyReader io.Reader
+	return c.doRequestWithHeader(ctx, method, path, body, nil)
+}
+
+// doRequestWithHeader performs an authenticated HTTP request with extra headers.
+// Idempotent requests are retried with backoff on network errors, 429 and 5xx
+// responses; a 401 triggers a single token refresh and resend.
+func (c *Client) doRequestWithHeader(ctx context.Context, method, path string, body interface{}, header http.Header) (*http.Response, error) {
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal body: %w", err)
 		}
+	}
+
+	retryable := c.retry.isRetryableMethod(method, header)
+	refreshed := false
+	var waited time.Duration
+
+	for attempt := 1; ; attempt++ {
+		token := c.currentToken()
+		resp, err := c.send(ctx, method, path, data, header, token)
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && !refreshed {
+			resp.Body.Close()
+
+			// Try to refresh token, then resend without counting an attempt
+			if err := c.refreshToken(ctx, token, method, path); err != nil {
+				return nil, fmt.Errorf("unauthorized and token refresh failed: %w", err)
+			}
+			refreshed = true
+			attempt--
+			continue
+		}
+
+		if !retryable || attempt >= c.retry.MaxAttempts {
+			return resp, err
+		}
+
+		var delay time.Duration
+		if err != nil {
+			if !isRetryableError(ctx, err) {
+				return nil, err
+			}
+			delay = c.retry.backoff(attempt)
+		} else {
+			if !isRetryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			if d, ok := retryAfter(resp); ok {
+				delay = d
+			} else {
+				delay = c.retry.backoff(attempt)
+			}
+		}
+
+		// Give up with the last outcome once the wait budget is spent
+		if waited+delay > c.retry.MaxWait {
+			c.logger.InfoContext(ctx, "retry budget exhausted", "method", method, "path", path, "attempt", attempt, "waited", waited)
+			return resp, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+		}
+		c.logger.InfoContext(ctx, "retrying request", "method", method, "path", path, "attempt", attempt+1, "max_attempts", c.retry.MaxAttempts, "delay", delay, "reason", reason)
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		waited += delay
+	}
+}
+
+// currentToken returns the token requests are currently sent with
+func (c *Client) currentToken() *auth.TokenInfo {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.token
+}
+
+// refreshToken replaces a token the server rejected. Concurrent callers are
+// serialised: if another request already replaced stale, its token is reused
+// rather than spending the refresh token a second time.
+func (c *Client) refreshToken(ctx context.Context, stale *auth.TokenInfo, method, path string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.currentToken() != stale {
+		return nil
+	}
+
+	c.logger.InfoContext(ctx, "refreshing access token after 401", "method", method, "path", path)
+	newToken, err := auth.RefreshAccessToken(stale.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	c.tokenMu.Lock()
+	c.token = newToken
+	c.tokenMu.Unlock()
+	return nil
+}
+
+// send performs a single attempt of a request, building a fresh body reader each time
+func (c *Client) send(ctx context.Context, method, path string, data []byte, header http.Header, token *auth.TokenInfo) (*http.Response, error) {
+	var bodyReader io.Reader
+	if data != nil {
 		bodyReader = bytes.NewReader(data)
 	}
 
@@ -57,7 +262,12 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token.AccessToken))
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -66,32 +276,15 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		// Try to refresh token
-		newToken, err := auth.RefreshAccessToken(c.token.RefreshToken)
-		if err != nil {
-			return nil, fmt.Errorf("unauthorized and token refresh failed: %w", err)
-		}
-		c.token = newToken
-
-		// Retry the request
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token.AccessToken))
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("retry request failed: %w", err)
-		}
-	}
-
 	return resp, nil
 }
 
-// decodeResponse decodes a JSON response
+// decodeResponse decodes a JSON response, returning an *Error for non-2xx statuses
 func decodeResponse(resp *http.Response, v interface{}) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return newError(resp)
 	}
 
 	if v != nil {
@@ -194,9 +387,16 @@ func (c *Client) GetScript(ctx context.Context, id int) (*Script, error) {
 	return &script, nil
 }
 
-// ExecuteScript executes a script on the specified agents
+// ExecuteScript executes a script on the specified agents.
+// The request is only retried on transient failures if it carries an
+// idempotency key and the server is configured as honouring it.
 func (c *Client) ExecuteScript(ctx context.Context, scriptID int, req ExecuteRequest) (*ExecuteResponse, error) {
-	resp, err := c.doRequest(ctx, "POST", fmt.Sprintf("/api/scripts/%d/execute", scriptID), req)
+	var header http.Header
+	if req.IdempotencyKey != "" {
+		header = http.Header{IdempotencyKeyHeader: {req.IdempotencyKey}}
+	}
+
+	resp, err := c.doRequestWithHeader(ctx, "POST", fmt.Sprintf("/api/scripts/%d/execute", scriptID), req, header)
 	if err != nil {
 		return nil, err
 	}
@@ -277,45 +477,3 @@ func (c *Client) ListRecentExecutions(ctx context.Context, limit int) ([]Executi
 
 	return executions, nil
 }
-
-// GetAllExecutionResults returns all results for an execution (fetches all pages)
-func (c *Client) GetAllExecutionResults(ctx context.Context, id string) ([]ExecutionResult, error) {
-	var allResults []ExecutionResult
-	page := 1
-	pageSize := 100
-
-	for {
-		results, err := c.GetExecutionResults(ctx, id, page, pageSize)
-		if err != nil {
-			return nil, err
-		}
-
-		allResults = append(allResults, results.Results...)
-
-		// Check if we have all results
-		if len(allResults) >= results.TotalCount || len(results.Results) < pageSize {
-			break
-		}
-
-		page++
-	}
-
-	return allResults, nil
-}
-
-// GetExecutionErrors returns all error results for an execution
-func (c *Client) GetExecutionErrors(ctx context.Context, id string) ([]ExecutionResult, error) {
-	allResults, err := c.GetAllExecutionResults(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	var errors []ExecutionResult
-	for _, r := range allResults {
-		if r.HasError {
-			errors = append(errors, r)
-		}
-	}
-
-	return errors, nil
-}
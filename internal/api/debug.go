@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// DefaultDebugBodyLimit is how much of each body is logged when body dumps are enabled
+const DefaultDebugBodyLimit = 4096
+
+// maxDebugCapture bounds how much of a body is buffered for redaction before truncation
+const maxDebugCapture = 256 * 1024
+
+// WithLogger logs every request and retry decision to logger.
+// Requests are logged at debug level, retries and token refreshes at info level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithBodyLogging includes request and response bodies in debug logs,
+// redacted and truncated to limit bytes (DefaultDebugBodyLimit if limit <= 0)
+func WithBodyLogging(limit int) Option {
+	return func(o *clientOptions) {
+		if limit <= 0 {
+			limit = DefaultDebugBodyLimit
+		}
+		o.bodyLimit = limit
+	}
+}
+
+// discardLogger is used when no logger is configured
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// loggingTransport logs each HTTP round trip once its response body is closed
+type loggingTransport struct {
+	next      http.RoundTripper
+	logger    *slog.Logger
+	bodyLimit int
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !t.logger.Enabled(ctx, slog.LevelDebug) {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			t.logger.WarnContext(ctx, "http request failed", "method", req.Method, "url", req.URL.String(), "error", err)
+		}
+		return resp, err
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+	}
+
+	if t.bodyLimit > 0 && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, maxDebugCapture))
+			body.Close()
+			if len(data) > 0 {
+				attrs = append(attrs, slog.String("request_body", t.dumpBody(data)))
+			}
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		attrs = append(attrs, slog.Duration("latency", latency), slog.String("error", err.Error()))
+		t.logger.LogAttrs(ctx, slog.LevelWarn, "http request failed", attrs...)
+		return nil, err
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.Duration("latency", latency))
+	if id := resp.Header.Get("X-Request-Id"); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	resp.Body = &loggedBody{
+		ReadCloser: resp.Body,
+		transport:  t,
+		req:        req,
+		attrs:      attrs,
+		start:      start,
+		capture:    t.bodyLimit > 0,
+	}
+	return resp, nil
+}
+
+// dumpBody redacts and truncates a body for logging. A capture cut off at
+// maxDebugCapture is no longer valid JSON and is redacted by key scanning.
+func (t *loggingTransport) dumpBody(data []byte) string {
+	data = redactBody(data)
+	if len(data) > t.bodyLimit {
+		return string(data[:t.bodyLimit]) + "...(truncated)"
+	}
+	return string(data)
+}
+
+// loggedBody counts response bytes and emits the log record when closed
+type loggedBody struct {
+	io.ReadCloser
+	transport *loggingTransport
+	req       *http.Request
+	attrs     []slog.Attr
+	start     time.Time
+	capture   bool
+	buf       bytes.Buffer
+	size      int64
+	logged    bool
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if b.capture && b.buf.Len() < maxDebugCapture {
+		b.buf.Write(p[:min(n, maxDebugCapture-b.buf.Len())])
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.logged {
+		return err
+	}
+	b.logged = true
+
+	attrs := append(b.attrs, slog.Int64("bytes", b.size), slog.Duration("elapsed", time.Since(b.start)))
+	if b.capture && b.buf.Len() > 0 {
+		attrs = append(attrs, slog.String("response_body", b.transport.dumpBody(b.buf.Bytes())))
+	}
+	b.transport.logger.LogAttrs(b.req.Context(), slog.LevelDebug, "http request", attrs...)
+	return err
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNotLoggedIn is returned when no usable credentials are stored
+var ErrNotLoggedIn = errors.New("not logged in. Run 'binmave login' first")
+
+// maxErrorBody limits how much of an unstructured error body is kept
+const maxErrorBody = 512
+
+// requestIDHeaders are response headers that may carry a request identifier,
+// in order of preference
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Correlation-Id", "X-Amz-Cf-Id"}
+
+// Error is returned for API responses with a non-2xx status.
+// Problem details (RFC 7807) fields are populated when the server sends them.
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	RequestID  string
+
+	// RFC 7807 problem details
+	Type     string
+	Title    string
+	Detail   string
+	Instance string
+	TraceID  string
+
+	// Body holds the (truncated) response body when it is not problem details
+	Body string
+}
+
+// problemDetails is the RFC 7807 response body (with the ASP.NET traceId extension)
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+	TraceID  string `json:"traceId"`
+}
+
+// newError builds an Error from a failed response, consuming its body
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var problem problemDetails
+	if err := json.Unmarshal(body, &problem); err == nil && (problem.Title != "" || problem.Detail != "") {
+		apiErr.Type = problem.Type
+		apiErr.Title = problem.Title
+		apiErr.Detail = problem.Detail
+		apiErr.Instance = problem.Instance
+		apiErr.TraceID = problem.TraceID
+		return apiErr
+	}
+
+	text := strings.TrimSpace(string(body))
+	if len(text) > maxErrorBody {
+		text = text[:maxErrorBody] + "..."
+	}
+	apiErr.Body = text
+
+	return apiErr
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "API error (status %d", e.StatusCode)
+	if e.Method != "" {
+		fmt.Fprintf(&b, ", %s %s", e.Method, e.Path)
+	}
+	b.WriteString(")")
+
+	if msg := e.Message(); msg != "" {
+		b.WriteString(": ")
+		b.WriteString(msg)
+	}
+
+	if id := e.Reference(); id != "" {
+		fmt.Fprintf(&b, " [ref %s]", id)
+	}
+
+	return b.String()
+}
+
+// Message returns the most descriptive message the server provided
+func (e *Error) Message() string {
+	switch {
+	case e.Title != "" && e.Detail != "":
+		return e.Title + ": " + e.Detail
+	case e.Detail != "":
+		return e.Detail
+	case e.Title != "":
+		return e.Title
+	}
+	return e.Body
+}
+
+// Reference returns an identifier to quote when reporting the error
+func (e *Error) Reference() string {
+	if e.TraceID != "" {
+		return e.TraceID
+	}
+	return e.RequestID
+}
+
+// StatusCode returns the HTTP status of an API error, or 0 if err is not one
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is a 401 response
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is a 403 response
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsConflict reports whether err is a 409 response
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsRateLimited reports whether err is a 429 response
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
+
+// IsBadRequest reports whether err is a 400 or 422 response
+func IsBadRequest(err error) bool {
+	code := StatusCode(err)
+	return code == http.StatusBadRequest || code == http.StatusUnprocessableEntity
+}
+
+// IsServerError reports whether err is a 5xx response
+func IsServerError(err error) bool {
+	return StatusCode(err) >= 500
+}
@@ -0,0 +1,382 @@
+// Package fake provides an in-memory implementation of api.Backend for tests.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// Execution holds everything the fake knows about one execution
+type Execution struct {
+	Execution api.Execution
+	Status    api.ExecutionStatus
+	Results   []api.ExecutionResult
+}
+
+// Submission records a call to ExecuteScript
+type Submission struct {
+	ScriptID int
+	Request  api.ExecuteRequest
+	Response api.ExecuteResponse
+}
+
+// Backend is an in-memory api.Backend.
+// Its fields may be populated directly before use; methods are safe for
+// concurrent use once the backend is shared.
+type Backend struct {
+	mu sync.Mutex
+
+	Agents     []api.Agent
+	Scripts    []api.Script
+	Executions map[string]*Execution
+
+	// Submissions records every accepted ExecuteScript call, in order
+	Submissions []Submission
+
+	// Errors maps a method name (e.g. "GetExecution") to an error it returns
+	Errors map[string]error
+
+	// Calls counts invocations per method name
+	Calls map[string]int
+
+	// Now returns the current time for created executions (default time.Now)
+	Now func() time.Time
+
+	nextID int
+}
+
+var _ api.Backend = (*Backend)(nil)
+
+// New creates an empty fake backend
+func New() *Backend {
+	return &Backend{
+		Executions: make(map[string]*Execution),
+		Errors:     make(map[string]error),
+		Calls:      make(map[string]int),
+	}
+}
+
+// AddExecution registers an execution with its status and results
+func (b *Backend) AddExecution(execution api.Execution, status api.ExecutionStatus, results []api.ExecutionResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.Executions[execution.ExecutionID] = &Execution{
+		Execution: execution,
+		Status:    status,
+		Results:   results,
+	}
+}
+
+// AddResults appends results to an execution and updates its status counts,
+// simulating agents reporting in
+func (b *Backend) AddResults(executionID string, results ...api.ExecutionResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	exec, ok := b.Executions[executionID]
+	if !ok {
+		return
+	}
+	for _, r := range results {
+		exec.Results = append(exec.Results, r)
+		exec.Status.Received++
+		if r.HasError {
+			exec.Status.Errors++
+		}
+	}
+	if exec.Status.Expected > 0 && exec.Status.Received >= exec.Status.Expected {
+		exec.Status.State = "Completed"
+	} else {
+		exec.Status.State = "Running"
+	}
+}
+
+// FailWith makes the named method return err until cleared with a nil err
+func (b *Backend) FailWith(method string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		delete(b.Errors, method)
+		return
+	}
+	b.Errors[method] = err
+}
+
+// call records an invocation and returns the injected error, if any.
+// It must be called with the lock held.
+func (b *Backend) call(method string) error {
+	if b.Calls == nil {
+		b.Calls = make(map[string]int)
+	}
+	b.Calls[method]++
+	return b.Errors[method]
+}
+
+// NotFound returns the error the real client produces for a 404 response
+func NotFound(method, path string) error {
+	return &api.Error{StatusCode: http.StatusNotFound, Method: method, Path: path, Title: "Not Found"}
+}
+
+// ListAgents returns the configured agents
+func (b *Backend) ListAgents(ctx context.Context) ([]api.Agent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("ListAgents"); err != nil {
+		return nil, err
+	}
+	return append([]api.Agent(nil), b.Agents...), nil
+}
+
+// GetAgentStats computes statistics from the configured agents
+func (b *Backend) GetAgentStats(ctx context.Context) (*api.AgentStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("GetAgentStats"); err != nil {
+		return nil, err
+	}
+
+	stats := &api.AgentStats{
+		Total:             len(b.Agents),
+		ByOperatingSystem: make(map[string]int),
+	}
+	for _, agent := range b.Agents {
+		switch strings.ToLower(agent.AgentStatus) {
+		case "online":
+			stats.Online++
+		case "offline":
+			stats.Offline++
+		case "expired":
+			stats.Expired++
+		case "expiring":
+			stats.Expiring++
+		}
+		stats.ByOperatingSystem[agent.OperatingSystem]++
+	}
+	return stats, nil
+}
+
+// ListScripts returns the configured scripts
+func (b *Backend) ListScripts(ctx context.Context) ([]api.Script, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("ListScripts"); err != nil {
+		return nil, err
+	}
+	return append([]api.Script(nil), b.Scripts...), nil
+}
+
+// GetScript returns a configured script by ID
+func (b *Backend) GetScript(ctx context.Context, id int) (*api.Script, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("GetScript"); err != nil {
+		return nil, err
+	}
+	for _, script := range b.Scripts {
+		if script.ScriptID == id {
+			s := script
+			return &s, nil
+		}
+	}
+	return nil, NotFound("GET", fmt.Sprintf("/api/scripts/%d", id))
+}
+
+// countTargets returns how many agents a filter selects: all of them for an
+// empty filter, those with the machine name for a machineName=<name> filter
+// and none for anything else
+func (b *Backend) countTargets(filter string) int {
+	if filter == "" {
+		return len(b.Agents)
+	}
+	name, ok := strings.CutPrefix(filter, "machineName=")
+	if !ok {
+		return 0
+	}
+	n := 0
+	for _, agent := range b.Agents {
+		if agent.MachineName == name {
+			n++
+		}
+	}
+	return n
+}
+
+// ExecuteScript creates a pending execution expecting the agents its filter selects.
+// A repeated idempotency key returns the original execution, as a server
+// honouring Idempotency-Key would.
+func (b *Backend) ExecuteScript(ctx context.Context, scriptID int, req api.ExecuteRequest) (*api.ExecuteResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("ExecuteScript"); err != nil {
+		return nil, err
+	}
+
+	if req.IdempotencyKey != "" {
+		for _, s := range b.Submissions {
+			if s.Request.IdempotencyKey == req.IdempotencyKey {
+				resp := s.Response
+				return &resp, nil
+			}
+		}
+	}
+
+	var script *api.Script
+	for i := range b.Scripts {
+		if b.Scripts[i].ScriptID == scriptID {
+			script = &b.Scripts[i]
+		}
+	}
+	if script == nil {
+		return nil, NotFound("POST", fmt.Sprintf("/api/scripts/%d/execute", scriptID))
+	}
+
+	now := time.Now
+	if b.Now != nil {
+		now = b.Now
+	}
+
+	b.nextID++
+	resp := api.ExecuteResponse{
+		ExecutionID:    fmt.Sprintf("00000000-0000-4000-8000-%012d", b.nextID),
+		ScriptID:       scriptID,
+		ScriptName:     script.Name,
+		Created:        now(),
+		ExpectedAgents: b.countTargets(req.FilterGridString),
+	}
+
+	if b.Executions == nil {
+		b.Executions = make(map[string]*Execution)
+	}
+	b.Executions[resp.ExecutionID] = &Execution{
+		Execution: api.Execution{
+			ExecutionID:      resp.ExecutionID,
+			ScriptID:         scriptID,
+			ScriptName:       script.Name,
+			Created:          resp.Created,
+			CreatedBy:        "fake",
+			ScriptTimeout:    req.ScriptTimeout,
+			FilterGridString: req.FilterGridString,
+			Inputs:           req.Inputs,
+		},
+		Status: api.ExecutionStatus{Expected: resp.ExpectedAgents, State: "Pending"},
+	}
+	b.Submissions = append(b.Submissions, Submission{ScriptID: scriptID, Request: req, Response: resp})
+
+	return &resp, nil
+}
+
+// ListRecentExecutions returns executions newest first
+func (b *Backend) ListRecentExecutions(ctx context.Context, limit int) ([]api.ExecutionListItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("ListRecentExecutions"); err != nil {
+		return nil, err
+	}
+
+	var items []api.ExecutionListItem
+	for _, exec := range b.Executions {
+		items = append(items, api.ExecutionListItem{
+			ExecutionID: exec.Execution.ExecutionID,
+			ScriptID:    exec.Execution.ScriptID,
+			ScriptName:  exec.Execution.ScriptName,
+			Created:     exec.Execution.Created,
+			CreatedBy:   exec.Execution.CreatedBy,
+			Expected:    exec.Status.Expected,
+			Received:    exec.Status.Received,
+			Errors:      exec.Status.Errors,
+			State:       exec.Status.State,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Created.After(items[j].Created)
+	})
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// GetExecution returns an execution by ID
+func (b *Backend) GetExecution(ctx context.Context, id string) (*api.Execution, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("GetExecution"); err != nil {
+		return nil, err
+	}
+	exec, ok := b.Executions[id]
+	if !ok {
+		return nil, NotFound("GET", "/api/scripts/executions/"+id)
+	}
+	e := exec.Execution
+	return &e, nil
+}
+
+// GetExecutionStatus returns the status of an execution
+func (b *Backend) GetExecutionStatus(ctx context.Context, id string) (*api.ExecutionStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("GetExecutionStatus"); err != nil {
+		return nil, err
+	}
+	exec, ok := b.Executions[id]
+	if !ok {
+		return nil, NotFound("GET", "/api/scripts/executions/"+id+"/status")
+	}
+	s := exec.Status
+	return &s, nil
+}
+
+// GetExecutionResults returns one page of an execution's results
+func (b *Backend) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.call("GetExecutionResults"); err != nil {
+		return nil, err
+	}
+	exec, ok := b.Executions[id]
+	if !ok {
+		return nil, NotFound("GET", "/api/scripts/executions/"+id+"/results")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 100
+	}
+
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(exec.Results) {
+		start = len(exec.Results)
+	}
+	if end > len(exec.Results) {
+		end = len(exec.Results)
+	}
+
+	return &api.ExecutionResultsPage{
+		Results:    append([]api.ExecutionResult(nil), exec.Results[start:end]...),
+		TotalCount: len(exec.Results),
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
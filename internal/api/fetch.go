@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// DefaultPageSize is the number of results requested per page
+const DefaultPageSize = config.DefaultPageSize
+
+// DefaultFetchConcurrency is the number of result pages fetched in parallel
+const DefaultFetchConcurrency = config.DefaultFetchConcurrency
+
+// maxFetchConcurrency bounds the worker pool regardless of configuration
+const maxFetchConcurrency = 16
+
+// FetchProgress reports how far a multi-page fetch has progressed
+type FetchProgress struct {
+	Page    int // Pages fetched so far
+	Pages   int // Total pages, as known from the first page
+	Results int // Results fetched so far
+	Total   int // Total results reported by the server
+}
+
+// FetchOption configures GetAllExecutionResults
+type FetchOption func(*fetchOptions)
+
+type fetchOptions struct {
+	pageSize    int
+	concurrency int
+	progress    func(FetchProgress)
+}
+
+// WithPageSize sets the number of results requested per page
+func WithPageSize(n int) FetchOption {
+	return func(o *fetchOptions) {
+		if n > 0 {
+			o.pageSize = n
+		}
+	}
+}
+
+// WithConcurrency sets how many pages are fetched in parallel
+func WithConcurrency(n int) FetchOption {
+	return func(o *fetchOptions) {
+		if n > 0 {
+			o.concurrency = min(n, maxFetchConcurrency)
+		}
+	}
+}
+
+// WithProgress calls fn after each page is consumed, on the consuming goroutine
+func WithProgress(fn func(FetchProgress)) FetchOption {
+	return func(o *fetchOptions) {
+		o.progress = fn
+	}
+}
+
+// newFetchOptions returns the configured defaults with opts applied
+func newFetchOptions(opts []FetchOption) fetchOptions {
+	o := fetchOptions{pageSize: DefaultPageSize, concurrency: DefaultFetchConcurrency}
+
+	cfg := config.Get()
+	WithPageSize(cfg.PageSize)(&o)
+	WithConcurrency(cfg.FetchConcurrency)(&o)
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// GetAllExecutionResults returns all results for an execution.
+// The first page reveals the total count; the remaining pages are fetched
+// concurrently and reassembled in order.
+func GetAllExecutionResults(ctx context.Context, b Backend, id string, opts ...FetchOption) ([]ExecutionResult, error) {
+	var allResults []ExecutionResult
+	for result, err := range StreamExecutionResults(ctx, b, id, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		allResults = append(allResults, result)
+	}
+	return allResults, nil
+}
+
+// pageResult is the outcome of one page request
+type pageResult struct {
+	page *ExecutionResultsPage
+	err  error
+}
+
+// StreamExecutionResults yields the results of an execution in order, one page
+// at a time, so memory use stays bounded regardless of the execution size.
+// Up to the configured concurrency of pages are fetched ahead of the consumer.
+// Iteration stops at the first error, which is yielded with a zero result.
+func StreamExecutionResults(ctx context.Context, b Backend, id string, opts ...FetchOption) iter.Seq2[ExecutionResult, error] {
+	o := newFetchOptions(opts)
+
+	return func(yield func(ExecutionResult, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		var pending []chan pageResult
+
+		// No fetch outlives the iteration, even when the consumer stops early
+		defer func() {
+			cancel()
+			for _, ch := range pending {
+				<-ch
+			}
+		}()
+
+		current, err := b.GetExecutionResults(ctx, id, 1, o.pageSize)
+		if err != nil {
+			yield(ExecutionResult{}, err)
+			return
+		}
+
+		// A server may cap the page size below the requested one; its pages
+		// are then numbered by the size of the first page
+		perPage := o.pageSize
+		if n := len(current.Results); n > 0 && n < perPage && n < current.TotalCount {
+			perPage = n
+		}
+		pages := max((current.TotalCount+perPage-1)/perPage, 1)
+		next := 2
+
+		// fetch starts fetching the next page in the background
+		fetch := func() {
+			ch := make(chan pageResult, 1)
+			go func(page int) {
+				results, err := b.GetExecutionResults(ctx, id, page, o.pageSize)
+				ch <- pageResult{page: results, err: err}
+			}(next)
+			pending = append(pending, ch)
+			next++
+		}
+
+		fetched := 0
+		for page := 1; ; page++ {
+			for len(pending) < o.concurrency && next <= pages {
+				fetch()
+			}
+
+			for _, result := range current.Results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+			fetched += len(current.Results)
+
+			if o.progress != nil {
+				pages = max(pages, page)
+				o.progress(FetchProgress{Page: page, Pages: pages, Results: fetched, Total: max(current.TotalCount, fetched)})
+			}
+
+			if len(pending) == 0 {
+				// Stop on an empty page or once the reported total is reached;
+				// results that arrived while fetching are picked up one page at a time
+				if fetched >= current.TotalCount || len(current.Results) == 0 {
+					return
+				}
+				pages = next
+				fetch()
+			}
+
+			outcome := <-pending[0]
+			pending = pending[1:]
+			if outcome.err != nil {
+				yield(ExecutionResult{}, outcome.err)
+				return
+			}
+			current = outcome.page
+		}
+	}
+}
+
+// ErrResultsChanged is returned by FetchNewExecutionResults when the results
+// already loaded no longer match the server's pages and a full reload is needed
+var ErrResultsChanged = errors.New("execution results changed since they were loaded")
+
+// ResultKey identifies a result within an execution.
+// Results without an ID are keyed by agent, as each agent reports once.
+func ResultKey(r ExecutionResult) string {
+	if r.ResultID != 0 {
+		return fmt.Sprintf("#%d", r.ResultID)
+	}
+	return "agent:" + r.AgentID
+}
+
+// FetchNewExecutionResults returns the results that follow the already loaded
+// ones, given their keys in order. Only the page holding the last loaded result
+// and later pages are requested. If the loaded results do not line up with the
+// server's ordering, ErrResultsChanged is returned.
+func FetchNewExecutionResults(ctx context.Context, b Backend, id string, loaded []string, opts ...FetchOption) ([]ExecutionResult, error) {
+	o := newFetchOptions(opts)
+
+	perPage := o.pageSize
+	page := len(loaded)/perPage + 1
+	offset := (page - 1) * perPage
+
+	var newResults []ExecutionResult
+	for {
+		results, err := b.GetExecutionResults(ctx, id, page, o.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if results.TotalCount < len(loaded) {
+			return nil, ErrResultsChanged
+		}
+
+		// A short page before the end means the server caps the page size;
+		// start again from the page that holds the last loaded result
+		if n := len(results.Results); n > 0 && n < perPage && offset+n < results.TotalCount {
+			perPage = n
+			page = len(loaded)/perPage + 1
+			offset = (page - 1) * perPage
+			newResults = nil
+			continue
+		}
+
+		for i, r := range results.Results {
+			if pos := offset + i; pos < len(loaded) {
+				if ResultKey(r) != loaded[pos] {
+					return nil, ErrResultsChanged
+				}
+				continue
+			}
+			newResults = append(newResults, r)
+		}
+
+		offset += len(results.Results)
+		if offset >= results.TotalCount || len(results.Results) == 0 {
+			return newResults, nil
+		}
+		page++
+	}
+}
+
+// GetExecutionErrors returns all error results for an execution
+func GetExecutionErrors(ctx context.Context, b Backend, id string) ([]ExecutionResult, error) {
+	var errors []ExecutionResult
+	for r, err := range StreamExecutionResults(ctx, b, id) {
+		if err != nil {
+			return nil, err
+		}
+		if r.HasError {
+			errors = append(errors, r)
+		}
+	}
+
+	return errors, nil
+}
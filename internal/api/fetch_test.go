@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// cappedBackend serves total results in pages of at most limit results,
+// whatever page size is requested, like a server with a page size cap
+type cappedBackend struct {
+	Backend
+	total int
+	limit int
+}
+
+func (b *cappedBackend) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*ExecutionResultsPage, error) {
+	size := min(pageSize, b.limit)
+	start := min((page-1)*size, b.total)
+	end := min(start+size, b.total)
+
+	results := make([]ExecutionResult, 0, end-start)
+	for i := start; i < end; i++ {
+		results = append(results, ExecutionResult{ResultID: i + 1, AgentID: fmt.Sprintf("agent-%d", i+1)})
+	}
+	return &ExecutionResultsPage{Results: results, TotalCount: b.total, Page: page, PageSize: size}, nil
+}
+
+func TestStreamExecutionResultsPageSizeCap(t *testing.T) {
+	b := &cappedBackend{total: 25, limit: 10}
+
+	for _, concurrency := range []int{1, 4} {
+		var ids []int
+		for result, err := range StreamExecutionResults(context.Background(), b, "exec", WithPageSize(100), WithConcurrency(concurrency)) {
+			if err != nil {
+				t.Fatalf("concurrency %d: %v", concurrency, err)
+			}
+			ids = append(ids, result.ResultID)
+		}
+		if len(ids) != b.total {
+			t.Fatalf("concurrency %d: streamed %d results, want %d", concurrency, len(ids), b.total)
+		}
+		for i, id := range ids {
+			if id != i+1 {
+				t.Fatalf("concurrency %d: result %d has ID %d", concurrency, i, id)
+			}
+		}
+	}
+}
+
+func TestFetchNewExecutionResultsPageSizeCap(t *testing.T) {
+	b := &cappedBackend{total: 25, limit: 10}
+
+	var loaded []string
+	for i := 1; i <= 12; i++ {
+		loaded = append(loaded, fmt.Sprintf("#%d", i))
+	}
+
+	results, err := FetchNewExecutionResults(context.Background(), b, "exec", loaded, WithPageSize(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 13 || results[0].ResultID != 13 || results[12].ResultID != 25 {
+		t.Fatalf("got %d new results, want results 13 to 25", len(results))
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// recentExecutionsScan is how many recent executions are inspected when
+// looking for submissions whose response was lost
+const recentExecutionsScan = 25
+
+// NewIdempotencyKey returns a random (version 4) UUID for use as an idempotency key
+func NewIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock
+		return fmt.Sprintf("cli-%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// IsAmbiguous reports whether a failed request may still have been processed
+// by the server: the response was lost, timed out, or was a server error.
+// Client errors (4xx) mean the request was definitely rejected.
+func IsAmbiguous(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+// FindRecentExecutions lists the executions of scriptID created at or after
+// since whose filter and inputs match req. They are candidates for a
+// submission that the server accepted even though the client saw a failure;
+// any of them may equally have been started by someone else.
+func FindRecentExecutions(ctx context.Context, b Backend, scriptID int, req ExecuteRequest, since time.Time) ([]*Execution, error) {
+	recent, err := b.ListRecentExecutions(ctx, recentExecutionsScan)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Execution
+	for _, item := range recent {
+		if item.ScriptID != scriptID || item.Created.Before(since) {
+			continue
+		}
+
+		execution, err := b.GetExecution(ctx, item.ExecutionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if execution.FilterGridString == req.FilterGridString && sameInputs(execution.Inputs, req.Inputs) {
+			matches = append(matches, execution)
+		}
+	}
+
+	return matches, nil
+}
+
+// sameInputs compares two input lists by key and value, ignoring order
+func sameInputs(a, b []ScriptInput) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	values := make(map[string]string, len(a))
+	for _, input := range a {
+		values[input.Key] = input.Value
+	}
+	for _, input := range b {
+		if v, ok := values[input.Key]; !ok || v != input.Value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secret values in recorded and logged traffic
+const redacted = "REDACTED"
+
+// sensitiveHeaders are request/response headers whose values are never recorded
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// sensitiveKeys are JSON object keys (compared case-insensitively) whose values are redacted
+var sensitiveKeys = []string{"access_token", "refresh_token", "id_token", "token", "password", "client_secret", "secret", "authorization"}
+
+// sensitiveJSONKey matches a sensitive JSON key up to the start of its value
+var sensitiveJSONKey = regexp.MustCompile(`(?i)"(` + sensitiveKeyPattern() + `)"\s*:\s*`)
+
+// sensitiveFormKey matches a sensitive key and its value in a form-encoded or query string
+var sensitiveFormKey = regexp.MustCompile(`(?i)(^|[&?\s])(` + sensitiveKeyPattern() + `)=[^&\s]*`)
+
+func sensitiveKeyPattern() string {
+	quoted := make([]string, len(sensitiveKeys))
+	for i, key := range sensitiveKeys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// redactHeader returns a copy of h with sensitive values replaced
+func redactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+// redactBody replaces sensitive values in a JSON body. Bodies that do not
+// parse, such as truncated captures or form posts, are scanned for sensitive
+// keys instead.
+func redactBody(body []byte) []byte {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return redactScan(body)
+	}
+	if !redactValue(data) {
+		return body
+	}
+	out, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactScan redacts the values of sensitive keys found by pattern, without
+// parsing the body. String values are redacted up to their closing quote or
+// the end of the body; an object or array value cannot be delimited reliably
+// in a truncated body, so nothing after it is kept.
+func redactScan(body []byte) []byte {
+	body = sensitiveFormKey.ReplaceAll(body, []byte("${1}${2}="+redacted))
+
+	var out bytes.Buffer
+	rest := body
+	for {
+		loc := sensitiveJSONKey.FindIndex(rest)
+		if loc == nil {
+			out.Write(rest)
+			return out.Bytes()
+		}
+		out.Write(rest[:loc[1]])
+		rest = rest[loc[1]:]
+
+		switch {
+		case len(rest) == 0:
+			return out.Bytes()
+		case rest[0] == '"':
+			out.WriteString(`"` + redacted + `"`)
+			rest = rest[stringEnd(rest):]
+		case rest[0] == '{' || rest[0] == '[':
+			out.WriteString(`"` + redacted + `"...(rest not logged)`)
+			return out.Bytes()
+		}
+	}
+}
+
+// stringEnd returns the index just past the JSON string starting at s[0],
+// or len(s) if the string is not terminated
+func stringEnd(s []byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// redactValue redacts sensitive keys in place, reporting whether anything changed
+func redactValue(data interface{}) bool {
+	changed := false
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+				changed = true
+				continue
+			}
+			if redactValue(value) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactValue(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
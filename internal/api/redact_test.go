@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// secret is the value planted in every test body; none of its bytes may be logged
+const secret = "s3cr3tV4lu3"
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		keep string // Text that must survive redaction
+	}{
+		{"json", `{"access_token":"s3cr3tV4lu3","user":"alice"}`, `"user":"alice"`},
+		{"nested json", `{"data":[{"Password":"s3cr3tV4lu3"}],"n":1}`, `"n":1`},
+		{"upper case key", `{"TOKEN":"s3cr3tV4lu3","ok":true}`, `"ok":true`},
+		{"authorization key", `{"Authorization":"Bearer s3cr3tV4lu3","ok":true}`, `"ok":true`},
+		{"escaped quotes", `{"password":"a\"s3cr3tV4lu3\"b","next":"x"}`, `"next":"x"`},
+		{"truncated json", `{"user":"alice","refresh_token":"s3cr3tV4lu3","more":[1,2`, `"user":"alice"`},
+		{"truncated escaped quotes", `{"password":"a\"s3cr3tV4lu3\"b","next":"x","tr`, `"next":"x"`},
+		{"cut mid value", `{"user":"alice","Token":"s3cr3tV4`, `"user":"alice"`},
+		{"cut mid escape", `{"user":"alice","secret":"s3cr3tV4\`, `"user":"alice"`},
+		{"spaced key", `{"user":"alice", "password" :  "s3cr3tV4lu3", "a`, `"user":"alice"`},
+		{"nested secret truncated", `{"user":"alice","secret":{"value":"s3cr3tV4lu3"},"x`, `"user":"alice"`},
+		{"form", `grant_type=refresh_token&refresh_token=s3cr3tV4lu3&client_id=cli`, `client_id=cli`},
+		{"form upper case", `Password=s3cr3tV4lu3&user=alice`, `user=alice`},
+		{"query", `?client_secret=s3cr3tV4lu3&x=1`, `x=1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(redactBody([]byte(tt.body)))
+			if leaked(got) {
+				t.Errorf("redactBody(%s) = %s, leaks the secret", tt.body, got)
+			}
+			if !strings.Contains(got, tt.keep) {
+				t.Errorf("redactBody(%s) = %s, lost %s", tt.body, got, tt.keep)
+			}
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("authorization", "Bearer "+secret)
+	h.Set("Cookie", "session="+secret)
+	h.Set("Accept", "application/json")
+
+	got := redactHeader(h)
+	for name, values := range got {
+		for _, v := range values {
+			if leaked(v) {
+				t.Errorf("header %s = %q leaks the secret", name, v)
+			}
+		}
+	}
+	if got.Get("Accept") != "application/json" {
+		t.Errorf("Accept = %q", got.Get("Accept"))
+	}
+	if h.Get("Authorization") != "Bearer "+secret {
+		t.Error("redactHeader changed the original header")
+	}
+}
+
+// TestDebugLogRedactsTruncatedCapture logs a response larger than the debug
+// capture with secrets on both sides of the cut
+func TestDebugLogRedactsTruncatedCapture(t *testing.T) {
+	var body bytes.Buffer
+	body.WriteString(`{"access_token":"` + secret + `","rows":[`)
+	for body.Len() < maxDebugCapture-20 {
+		body.WriteString(`{"a":1},`)
+	}
+	body.WriteString(`{"token":"` + secret + `"}]}`)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	transport := &loggingTransport{
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(body.Bytes()))}, nil
+		}),
+		logger:    logger,
+		bodyLimit: 2 * maxDebugCapture,
+	}
+
+	req, _ := http.NewRequest("GET", "https://binmave.example/api/scripts/executions/x/results", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(logs.String(), "response_body") {
+		t.Fatalf("no response body logged: %.200s", logs.String())
+	}
+	if leaked(logs.String()) {
+		t.Error("debug log leaks the secret")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// leaked reports whether any run of four or more bytes of the secret appears in s
+func leaked(s string) bool {
+	for i := 0; i+4 <= len(secret); i++ {
+		if strings.Contains(s, secret[i:i+4]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// IdempotencyKeyHeader identifies a submission so that a server honouring it
+// can recognise a duplicate
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how transient failures are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts, including the first
+	BaseDelay   time.Duration // Backoff before the second attempt
+	MaxDelay    time.Duration // Cap on a single backoff
+	MaxWait     time.Duration // Total time budget spent waiting between attempts
+
+	// RetryKeyedPosts also retries POST requests that carry an idempotency
+	// key. Only safe when the server is known to honour the key.
+	RetryKeyedPosts bool
+}
+
+// DefaultRetryPolicy is used when the configuration does not override it
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	MaxWait:     30 * time.Second,
+}
+
+// retryPolicyFromConfig returns the default policy adjusted by the configuration
+func retryPolicyFromConfig() RetryPolicy {
+	policy := DefaultRetryPolicy
+	cfg := config.Get()
+
+	if cfg.RetryMaxAttempts > 0 {
+		policy.MaxAttempts = cfg.RetryMaxAttempts
+	}
+	if d, err := time.ParseDuration(cfg.RetryMaxWait); err == nil && d >= 0 {
+		policy.MaxWait = d
+	}
+	policy.RetryKeyedPosts = cfg.IdempotencyKeys
+
+	return policy
+}
+
+// backoff returns the delay before the given retry (1 = first retry) using
+// exponential backoff with full jitter
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.BaseDelay << (retry - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// isRetryableMethod reports whether a request can safely be sent twice.
+// POST requests only qualify when they carry an idempotency key and the
+// policy trusts the server to honour it.
+func (p RetryPolicy) isRetryableMethod(method string, header http.Header) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return p.RetryKeyedPosts && header.Get(IdempotencyKeyHeader) != ""
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(code int) bool {
+	if code == http.StatusTooManyRequests {
+		return true
+	}
+	return code >= 500 && code != http.StatusNotImplemented && code != http.StatusHTTPVersionNotSupported
+}
+
+// isRetryableError reports whether a transport error is worth retrying: a
+// timeout, a refused or reset connection, or a connection closed early.
+// Failures that would recur unchanged, such as TLS certificate errors,
+// unknown hosts and malformed URLs, are not retried.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the delay or until the context is done
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/auth"
+)
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// transportError wraps err the way http.Client reports a failed request
+func transportError(err error) error {
+	return fmt.Errorf("request failed: %w", &url.Error{Op: "Get", URL: "https://binmave.example/api", Err: err})
+}
+
+func TestIsRetryableError(t *testing.T) {
+	opError := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+	_, parseErr := url.Parse("http://[::1")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", transportError(timeoutError{}), true},
+		{"deadline exceeded", transportError(context.DeadlineExceeded), true},
+		{"connection refused", transportError(opError(syscall.ECONNREFUSED)), true},
+		{"connection reset", transportError(opError(syscall.ECONNRESET)), true},
+		{"EOF", transportError(io.EOF), true},
+		{"unexpected EOF", transportError(io.ErrUnexpectedEOF), true},
+		{"unknown authority", transportError(x509.UnknownAuthorityError{}), false},
+		{"hostname mismatch", transportError(x509.HostnameError{Host: "binmave.example", Certificate: &x509.Certificate{}}), false},
+		{"no such host", transportError(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "binmave.invalid", IsNotFound: true}}), false},
+		{"malformed URL", fmt.Errorf("failed to create request: %w", parseErr), false},
+		{"canceled", transportError(context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(context.Background(), tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if isRetryableError(ctx, transportError(timeoutError{})) {
+		t.Error("retried after the context was canceled")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(resp)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// An HTTP date in the future waits until then
+	resp := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}}
+	if got, ok := retryAfter(resp); !ok || got < 55*time.Second || got > time.Minute {
+		t.Errorf("retryAfter(+1m) = %v, %v", got, ok)
+	}
+}
+
+// retryClient returns a client for server retrying up to 3 attempts within maxWait
+func retryClient(t *testing.T, server *httptest.Server, maxWait time.Duration) *Client {
+	t.Helper()
+	token := &auth.TokenInfo{AccessToken: "token", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour)}
+	client, err := NewClientWithToken(server.URL, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxWait: maxWait}
+	return client
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxWait    time.Duration
+		want       int32
+	}{
+		// Backoff alone would fit the budget; Retry-After does not
+		{"wait beyond budget", "5", time.Second, 1},
+		{"immediate", "0", time.Second, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			_, err := retryClient(t, server, tt.maxWait).GetAgentStats(context.Background())
+			if StatusCode(err) != http.StatusServiceUnavailable {
+				t.Errorf("err = %v, want status 503", err)
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("%d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpOnPermanentTransportErrors(t *testing.T) {
+	// A TLS server whose certificate the client does not trust; each attempt
+	// opens a new connection
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := retryClient(t, server, time.Minute).GetAgentStats(context.Background())
+	if err == nil {
+		t.Fatal("request to an untrusted server succeeded")
+	}
+	if got := connections.Load(); got != 1 {
+		t.Errorf("%d connection attempts, want 1: certificate errors are not retried", got)
+	}
+}
@@ -1,6 +1,9 @@
 package api
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Agent represents an agent in the system
 type Agent struct {
@@ -55,6 +58,28 @@ type ExecuteRequest struct {
 	FilterGridString string        `json:"filterGridString"`
 	Inputs           []ScriptInput `json:"inputs,omitempty"`
 	CleanSandBox     bool          `json:"cleanSandBox"`
+
+	// IdempotencyKey is sent as a header so a server that honours it can
+	// recognise duplicate submissions
+	IdempotencyKey string `json:"-"`
+}
+
+// MachineNameFilter returns a FilterGridString that targets the agents with
+// the given machine name, in the documented machineName=<name> form. The form
+// has no quoting, so names with characters other than letters, digits, '-',
+// '_' and '.' are rejected rather than risk matching other agents.
+func MachineNameFilter(machineName string) (string, error) {
+	if machineName == "" {
+		return "", fmt.Errorf("agent has no machine name to target")
+	}
+	for _, r := range machineName {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return "", fmt.Errorf("machine name %q cannot be targeted with a machineName filter", machineName)
+		}
+	}
+	return "machineName=" + machineName, nil
 }
 
 // ExecuteResponse represents the response from executing a script
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims holds the claims decoded from an access token.
+// The token is decoded locally for display only; its signature is not verified.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	Roles     []string
+	Scopes    []string
+	Tenant    string
+	ClientID  string
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiresAt time.Time
+
+	// Permissions holds the "permission" or "permissions" claim
+	Permissions []string
+
+	// Raw contains every claim as it appears in the token payload
+	Raw map[string]interface{}
+}
+
+// ParseClaims decodes the payload of a JWT access token without verifying it
+func ParseClaims(accessToken string) (*Claims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse token payload: %w", err)
+	}
+
+	claims := &Claims{
+		Subject:   claimString(raw, "sub"),
+		Issuer:    claimString(raw, "iss"),
+		Audience:  claimStrings(raw, "aud"),
+		Roles:     claimStrings(raw, "role"),
+		Scopes:    claimScopes(raw),
+		ClientID:  claimString(raw, "client_id"),
+		IssuedAt:  claimTime(raw, "iat"),
+		NotBefore: claimTime(raw, "nbf"),
+		ExpiresAt: claimTime(raw, "exp"),
+		Raw:       raw,
+	}
+
+	if len(claims.Roles) == 0 {
+		claims.Roles = claimStrings(raw, "roles")
+	}
+
+	claims.Permissions = claimStrings(raw, "permission")
+	if len(claims.Permissions) == 0 {
+		claims.Permissions = claimStrings(raw, "permissions")
+	}
+
+	// Tenant claim naming differs between identity providers
+	for _, key := range []string{"tenant", "tenant_id", "tenantId", "tid"} {
+		if tenant := claimString(raw, key); tenant != "" {
+			claims.Tenant = tenant
+			break
+		}
+	}
+
+	return claims, nil
+}
+
+// claimString returns a string claim, or empty if missing
+func claimString(raw map[string]interface{}, key string) string {
+	switch v := raw[key].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// claimStrings returns a claim that may be a single string or an array of
+// strings. A single string is one value, even if it contains spaces.
+func claimStrings(raw map[string]interface{}, key string) []string {
+	switch v := raw[key].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// claimScopes returns the OAuth scope claim, which is a space-separated string
+// or an array of strings
+func claimScopes(raw map[string]interface{}) []string {
+	if v, ok := raw["scope"].(string); ok && v != "" {
+		return strings.Fields(v)
+	}
+	return claimStrings(raw, "scope")
+}
+
+// claimTime returns a NumericDate claim as a time, or the zero time if missing
+func claimTime(raw map[string]interface{}, key string) time.Time {
+	if v, ok := raw[key].(float64); ok {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+// jwt returns an unsigned token with the given JSON payload
+func jwt(payload string) string {
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestParseClaimsMultiValued(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		roles   []string
+		scopes  []string
+	}{
+		{
+			name:    "single strings",
+			payload: `{"role":"Read Only Analyst","scope":"openid IdentityServerApi"}`,
+			roles:   []string{"Read Only Analyst"},
+			scopes:  []string{"openid", "IdentityServerApi"},
+		},
+		{
+			name:    "arrays",
+			payload: `{"role":["Read Only Analyst","viewer"],"scope":["openid","IdentityServerApi"]}`,
+			roles:   []string{"Read Only Analyst", "viewer"},
+			scopes:  []string{"openid", "IdentityServerApi"},
+		},
+		{
+			name:    "roles claim",
+			payload: `{"roles":"Incident Responder"}`,
+			roles:   []string{"Incident Responder"},
+		},
+		{
+			name:    "empty",
+			payload: `{"role":"","scope":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseClaims(jwt(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseClaims: %v", err)
+			}
+			if !reflect.DeepEqual(claims.Roles, tt.roles) {
+				t.Errorf("roles = %q, want %q", claims.Roles, tt.roles)
+			}
+			if !reflect.DeepEqual(claims.Scopes, tt.scopes) {
+				t.Errorf("scopes = %q, want %q", claims.Scopes, tt.scopes)
+			}
+		})
+	}
+}
+
+func TestMultiWordRoleIsReadOnly(t *testing.T) {
+	claims, err := ParseClaims(jwt(`{"role":"Read Only Analyst"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := &Identity{Roles: claims.Roles, ReadOnlyRoles: []string{"read only analyst"}}
+	if d := identity.Decide(ExecuteScripts); d.Allowed {
+		t.Errorf("Read Only Analyst may execute scripts: %+v", d)
+	}
+	if d := identity.Decide(ViewExecutions); !d.Allowed {
+		t.Errorf("Read Only Analyst may not view executions: %+v", d)
+	}
+}
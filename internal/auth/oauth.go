@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/httpclient"
 )
 
 const (
@@ -139,7 +140,12 @@ func RefreshAccessToken(refreshToken string) (*TokenInfo, error) {
 		"refresh_token": {refreshToken},
 	}
 
-	resp, err := http.PostForm(tokenURL, data)
+	client, err := httpclient.New(30 * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.PostForm(tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
@@ -210,7 +216,12 @@ func exchangeCodeForToken(code, verifier string) (*TokenInfo, error) {
 		"code_verifier": {verifier},
 	}
 
-	resp, err := http.PostForm(tokenURL, data)
+	client, err := httpclient.New(30 * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.PostForm(tokenURL, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
@@ -292,7 +303,11 @@ func GetUserInfo(token *TokenInfo) (*UserInfo, error) {
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client, err := httpclient.New(10 * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user info: %w", err)
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// APIScope is the scope required to call the Binmave API
+const APIScope = "IdentityServerApi"
+
+// Permission is a class of CLI operation that may be restricted by role or scope
+type Permission string
+
+const (
+	ViewAgents     Permission = "agents:read"
+	ViewScripts    Permission = "scripts:read"
+	ExecuteScripts Permission = "scripts:execute"
+	ViewExecutions Permission = "executions:read"
+)
+
+// AllPermissions lists every permission in display order
+var AllPermissions = []Permission{ViewAgents, ViewScripts, ExecuteScripts, ViewExecutions}
+
+// Description returns a human readable description of the permission
+func (p Permission) Description() string {
+	switch p {
+	case ViewAgents:
+		return "view agents"
+	case ViewScripts:
+		return "view scripts"
+	case ExecuteScripts:
+		return "execute scripts"
+	case ViewExecutions:
+		return "view executions and results"
+	}
+	return string(p)
+}
+
+// Identity describes what the current token is allowed to do
+type Identity struct {
+	Roles  []string
+	Scopes []string
+
+	// Permissions are the permission claims of the token, such as
+	// "scripts:read"; they are honoured together with permission scopes
+	Permissions []string
+
+	// ReadOnlyRoles are role names (compared case-insensitively) that may
+	// view data but not execute scripts
+	ReadOnlyRoles []string
+}
+
+// Decision is the outcome of checking a permission
+type Decision struct {
+	Allowed bool
+	By      string // The role, scope or claim that decided, e.g. "role viewer"
+	Reason  string // Why the permission is denied
+}
+
+// NewIdentity builds an identity from a token's claims, falling back to the
+// scope stored alongside the token when the access token is not a JWT
+func NewIdentity(token *TokenInfo) *Identity {
+	identity := &Identity{Scopes: strings.Fields(token.Scope), ReadOnlyRoles: config.Get().ReadOnlyRoles}
+
+	if claims, err := ParseClaims(token.AccessToken); err == nil {
+		identity.Roles = claims.Roles
+		identity.Permissions = claims.Permissions
+		if len(claims.Scopes) > 0 {
+			identity.Scopes = claims.Scopes
+		}
+	}
+
+	return identity
+}
+
+// Role returns the roles as a single display string
+func (i *Identity) Role() string {
+	if len(i.Roles) == 0 {
+		return "(none)"
+	}
+	return strings.Join(i.Roles, ", ")
+}
+
+// IsReadOnly returns true if every role the identity holds is read-only
+func (i *Identity) IsReadOnly() bool {
+	if len(i.Roles) == 0 {
+		return false
+	}
+	for _, role := range i.Roles {
+		if !i.isReadOnlyRole(role) {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Identity) isReadOnlyRole(role string) bool {
+	for _, r := range i.ReadOnlyRoles {
+		if strings.EqualFold(role, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAPIScope returns true if the token grants API access.
+// Tokens without any scope information are given the benefit of the doubt.
+func (i *Identity) hasAPIScope() bool {
+	if len(i.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range i.Scopes {
+		if scope == APIScope {
+			return true
+		}
+	}
+	return false
+}
+
+// grant returns the scope or permission claim granting the permission, and
+// whether the token names any CLI permission at all
+func (i *Identity) grant(p Permission) (by string, named bool) {
+	sources := []struct {
+		kind   string
+		grants []string
+	}{{"scope", i.Scopes}, {"claim", i.Permissions}}
+
+	for _, source := range sources {
+		for _, grant := range source.grants {
+			if grant == string(p) {
+				return source.kind + " " + grant, true
+			}
+			for _, known := range AllPermissions {
+				if grant == string(known) {
+					named = true
+				}
+			}
+		}
+	}
+	return "", named
+}
+
+// Decide checks whether the identity is expected to be allowed the
+// permission and records what decided it. A scope or permission claim naming
+// the permission grants it; otherwise scripts cannot be executed by read-only
+// roles, and tokens that name other CLI permissions but not this one are
+// denied. Tokens that name no CLI permission at all predate permission scopes
+// and are given the benefit of the doubt. The server remains the final authority.
+func (i *Identity) Decide(p Permission) Decision {
+	if !i.hasAPIScope() {
+		return Decision{By: "scopes " + strings.Join(i.Scopes, " "), Reason: "token is missing the " + APIScope + " scope"}
+	}
+
+	by, named := i.grant(p)
+	if by != "" {
+		return Decision{Allowed: true, By: by}
+	}
+	if p == ExecuteScripts && i.IsReadOnly() {
+		return Decision{By: "role " + i.Role(), Reason: "role " + i.Role() + " is read-only"}
+	}
+	if named {
+		return Decision{By: "scopes and claims", Reason: "token does not grant " + string(p)}
+	}
+	if len(i.Roles) > 0 {
+		return Decision{Allowed: true, By: "role " + i.Role()}
+	}
+	return Decision{Allowed: true, By: "default (no roles or CLI scopes)"}
+}
+
+// Can reports whether the identity is expected to be allowed the permission,
+// with a reason when it is not
+func (i *Identity) Can(p Permission) (bool, string) {
+	d := i.Decide(p)
+	return d.Allowed, d.Reason
+}
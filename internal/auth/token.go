@@ -2,6 +2,8 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"os"
 	"path/filepath"
 	"time"
@@ -91,7 +93,9 @@ func getCredentialsPath() (string, error) {
 	return filepath.Join(configDir, config.CredentialsFile+".json"), nil
 }
 
-// GetValidToken returns a valid token, refreshing if necessary
+// GetValidToken returns a valid token, refreshing if necessary. It returns
+// nil if there is no usable login, and an error wrapping a net.Error if the
+// token has expired and the server could not be reached to renew it.
 func GetValidToken() (*TokenInfo, error) {
 	token, err := LoadToken()
 	if err != nil {
@@ -110,7 +114,12 @@ func GetValidToken() (*TokenInfo, error) {
 	if token.RefreshToken != "" {
 		newToken, err := RefreshAccessToken(token.RefreshToken)
 		if err != nil {
-			// Refresh failed, return nil to trigger new login
+			// An unreachable server says nothing about the login, so report it
+			var netErr net.Error
+			if errors.As(err, &netErr) {
+				return nil, err
+			}
+			// Refresh was rejected, return nil to trigger new login
 			return nil, nil
 		}
 		return newToken, nil
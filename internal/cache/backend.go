@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// Backend serves completed executions from the cache and fills the cache as
+// results of completed executions are fetched through it.
+// All other calls go to the wrapped backend.
+type Backend struct {
+	api.Backend
+	store   *Store
+	offline bool
+
+	// mu guards the maps below; it is never held during network or disk I/O
+	mu       sync.Mutex
+	entries  map[string]*Entry  // Cache hits seen in this process
+	finished map[string]bool    // Whether an execution is known to be finished
+	writers  map[string]*Writer // In-progress writes by execution ID
+	failed   map[string]bool    // Executions that could not be cached this run
+}
+
+var _ api.Backend = (*Backend)(nil)
+
+// NewBackend wraps upstream with the cache
+func NewBackend(upstream api.Backend, store *Store) *Backend {
+	return &Backend{
+		Backend:  upstream,
+		store:    store,
+		entries:  make(map[string]*Entry),
+		finished: make(map[string]bool),
+		writers:  make(map[string]*Writer),
+		failed:   make(map[string]bool),
+	}
+}
+
+// Store returns the underlying cache
+func (b *Backend) Store() *Store {
+	return b.store
+}
+
+// Cached returns the cache entry of an execution, or nil if it is not cached.
+// An entry is read from disk and touched on first use.
+func (b *Backend) Cached(id string) *Entry {
+	b.mu.Lock()
+	entry, ok := b.entries[id]
+	b.mu.Unlock()
+	if ok {
+		return entry
+	}
+
+	entry, err := b.store.Get(id)
+	if err != nil || entry == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	if seen, ok := b.entries[id]; ok {
+		b.mu.Unlock()
+		return seen
+	}
+	b.entries[id] = entry
+	b.mu.Unlock()
+
+	b.store.Touch(id)
+	return entry
+}
+
+// GetExecution returns the cached execution or fetches it
+func (b *Backend) GetExecution(ctx context.Context, id string) (*api.Execution, error) {
+	if entry := b.Cached(id); entry != nil {
+		execution := entry.Execution
+		return &execution, nil
+	}
+	return b.Backend.GetExecution(ctx, id)
+}
+
+// GetExecutionStatus returns the cached status or fetches it
+func (b *Backend) GetExecutionStatus(ctx context.Context, id string) (*api.ExecutionStatus, error) {
+	if entry := b.Cached(id); entry != nil {
+		status := entry.Status
+		return &status, nil
+	}
+
+	status, err := b.Backend.GetExecutionStatus(ctx, id)
+	if err == nil {
+		b.mu.Lock()
+		b.finished[id] = isFinished(status)
+		b.mu.Unlock()
+	}
+	return status, err
+}
+
+// GetExecutionResults serves a page from the cache, or fetches it and records
+// it if the execution has finished
+func (b *Backend) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	if entry := b.Cached(id); entry != nil {
+		results, err := b.store.Results(entry, page, pageSize)
+		if err == nil {
+			return results, nil
+		}
+		// A damaged entry is dropped and the page fetched again
+		b.mu.Lock()
+		delete(b.entries, id)
+		b.mu.Unlock()
+		b.store.Remove(id)
+	}
+
+	results, err := b.Backend.GetExecutionResults(ctx, id, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	b.record(ctx, id, page, pageSize, results)
+	return results, nil
+}
+
+// record writes a fetched page to the cache and commits the entry once every
+// page of a finished execution has been seen. Cache failures are not reported;
+// the execution is simply not cached. Pages may be recorded concurrently.
+func (b *Backend) record(ctx context.Context, id string, page, pageSize int, results *api.ExecutionResultsPage) {
+	if results.TotalCount == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	failed := b.failed[id]
+	finished, known := b.finished[id]
+	b.mu.Unlock()
+	if failed {
+		return
+	}
+
+	if !known {
+		status, err := b.Backend.GetExecutionStatus(ctx, id)
+		if err != nil {
+			return
+		}
+		finished = isFinished(status)
+		b.mu.Lock()
+		b.finished[id] = finished
+		b.mu.Unlock()
+	}
+	if !finished {
+		return
+	}
+
+	w := b.writer(id, pageSize, results.TotalCount)
+	if w == nil {
+		return
+	}
+
+	// A server capping the page size returns pages that do not fill the
+	// layout of the entry; such an execution is not cached
+	if want := min(pageSize, results.TotalCount-(page-1)*pageSize); want > 0 && len(results.Results) != want {
+		b.abort(id, w)
+		return
+	}
+
+	if err := w.WritePage(page, results.Results); err != nil {
+		b.abort(id, w)
+		return
+	}
+	if !w.Complete() {
+		return
+	}
+
+	// Only the caller that removes the writer commits it
+	b.mu.Lock()
+	owner := b.writers[id] == w
+	if owner {
+		delete(b.writers, id)
+	}
+	b.mu.Unlock()
+	if !owner {
+		return
+	}
+
+	// Metadata is fetched fresh so the entry reflects the final state
+	status, err := b.Backend.GetExecutionStatus(ctx, id)
+	if err != nil || !isFinished(status) || status.Received != results.TotalCount {
+		w.Abort()
+		return
+	}
+	execution, err := b.Backend.GetExecution(ctx, id)
+	if err != nil {
+		w.Abort()
+		return
+	}
+	if err := w.Commit(*execution, *status); err != nil {
+		b.mu.Lock()
+		b.failed[id] = true
+		b.mu.Unlock()
+	}
+}
+
+// writer returns the in-progress write of an execution, starting one if
+// needed. Pages of a different shape cannot be combined, so a writer with
+// another page size or total count is replaced. It returns nil if the
+// execution cannot be cached.
+func (b *Backend) writer(id string, pageSize, totalCount int) *Writer {
+	b.mu.Lock()
+	w := b.writers[id]
+	if w != nil && w.PageSize() == pageSize && w.TotalCount() == totalCount {
+		b.mu.Unlock()
+		return w
+	}
+	b.mu.Unlock()
+
+	created, err := b.store.NewWriter(id, pageSize, totalCount)
+
+	b.mu.Lock()
+	if err != nil {
+		b.failed[id] = true
+		b.mu.Unlock()
+		return nil
+	}
+	// Another page may have started a suitable writer meanwhile
+	current := b.writers[id]
+	if current != nil && current.PageSize() == pageSize && current.TotalCount() == totalCount {
+		b.mu.Unlock()
+		created.Abort()
+		return current
+	}
+	b.writers[id] = created
+	b.mu.Unlock()
+
+	if current != nil {
+		current.Abort()
+	}
+	return created
+}
+
+// abort discards a failed write and stops caching the execution this run.
+// A writer that was already replaced is only discarded.
+func (b *Backend) abort(id string, w *Writer) {
+	b.mu.Lock()
+	if b.writers[id] == w {
+		delete(b.writers, id)
+		b.failed[id] = true
+	}
+	b.mu.Unlock()
+
+	w.Abort()
+}
+
+// isFinished reports whether an execution will not receive more results
+func isFinished(status *api.ExecutionStatus) bool {
+	return status.State == "Completed" || status.State == "Failed"
+}
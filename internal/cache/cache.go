@@ -0,0 +1,409 @@
+// Package cache stores completed executions on disk so their results can be
+// opened again without downloading them.
+//
+// Each server and profile (the signed-in account) gets its own directory
+// under ~/.binmave/cache/<server>/<profile>. An execution is stored as
+// meta.json plus one gzip-compressed NDJSON file per result page.
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// DirName is the cache directory inside the config directory
+const DirName = "cache"
+
+const (
+	metaFile        = "meta.json"
+	partialPrefix   = ".partial-"
+	lastProfileFile = ".last-profile"
+
+	// DefaultProfile is used when the account is not known
+	DefaultProfile = "default"
+
+	// partialMaxAge is how long an unfinished write is kept before pruning
+	partialMaxAge = time.Hour
+)
+
+// Entry describes a cached execution
+type Entry struct {
+	ExecutionID string              `json:"executionId"`
+	Server      string              `json:"server"`
+	Profile     string              `json:"profile,omitempty"`
+	Execution   api.Execution       `json:"execution"`
+	Status      api.ExecutionStatus `json:"status"`
+	TotalCount  int                 `json:"totalCount"`
+	PageSize    int                 `json:"pageSize"`
+	Pages       int                 `json:"pages"`
+	Size        int64               `json:"size"`
+	CachedAt    time.Time           `json:"cachedAt"`
+	LastUsed    time.Time           `json:"lastUsed"`
+}
+
+// Store is the cache for one server and profile
+type Store struct {
+	dir     string
+	server  string
+	profile string
+	maxSize int64
+}
+
+// Root returns the directory holding the caches of all servers and profiles
+func Root() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, DirName), nil
+}
+
+// Open returns the cache for server and profile, creating its directory if
+// needed. An empty profile is DefaultProfile. maxSize limits the total size of
+// cached entries (0 means unlimited).
+func Open(server, profile string, maxSize int64) (*Store, error) {
+	root, err := Root()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	dir := filepath.Join(root, serverDirName(server), dirName(profile))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Store{dir: dir, server: server, profile: profile, maxSize: maxSize}, nil
+}
+
+// RememberProfile records profile as the last one signed in to server, so its
+// cache can still be found once the login is gone
+func RememberProfile(server, profile string) error {
+	root, err := Root()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(root, serverDirName(server))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, lastProfileFile), []byte(profile), 0600)
+}
+
+// LastProfile returns the profile last signed in to server, or DefaultProfile
+// if none was recorded
+func LastProfile(server string) string {
+	root, err := Root()
+	if err != nil {
+		return DefaultProfile
+	}
+	data, err := os.ReadFile(filepath.Join(root, serverDirName(server), lastProfileFile))
+	if err != nil || len(data) == 0 {
+		return DefaultProfile
+	}
+	return string(data)
+}
+
+// serverDirName turns a server URL into a directory name
+func serverDirName(server string) string {
+	name := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		name = u.Host + strings.TrimSuffix(u.Path, "/")
+	}
+	return dirName(name)
+}
+
+// dirName replaces characters that are not safe in a directory name
+func dirName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+	// "." and ".." would not be a directory of their own
+	if strings.Trim(name, ".") == "" {
+		return "_"
+	}
+	return name
+}
+
+// Dir returns the directory of this store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Profile returns the profile this store caches executions for
+func (s *Store) Profile() string {
+	return s.profile
+}
+
+// MaxSize returns the configured size limit (0 means unlimited)
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// entryDir returns the directory of an execution, rejecting IDs that are not plain names
+func (s *Store) entryDir(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid execution ID %q", id)
+	}
+	return filepath.Join(s.dir, id), nil
+}
+
+// Get returns the cached entry for an execution, or nil if it is not cached
+func (s *Store) Get(id string) (*Entry, error) {
+	dir, err := s.entryDir(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("corrupt cache entry %s: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// ReadPage reads one stored page of an entry (1-based, in the entry's page size)
+func (s *Store) ReadPage(entry *Entry, page int) ([]api.ExecutionResult, error) {
+	dir, err := s.entryDir(entry.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, pageFile(page)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt cache page: %w", err)
+	}
+	defer gz.Close()
+
+	var results []api.ExecutionResult
+	decoder := json.NewDecoder(gz)
+	for decoder.More() {
+		var r api.ExecutionResult
+		if err := decoder.Decode(&r); err != nil {
+			return nil, fmt.Errorf("corrupt cache page: %w", err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// Results returns the page of results an API call with page and pageSize would return
+func (s *Store) Results(entry *Entry, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = api.DefaultPageSize
+	}
+
+	if entry.PageSize < 1 {
+		return nil, fmt.Errorf("corrupt cache entry %s: invalid page size", entry.ExecutionID)
+	}
+
+	start := (page - 1) * pageSize
+	end := min(start+pageSize, entry.TotalCount)
+
+	results := []api.ExecutionResult{}
+	for stored := start/entry.PageSize + 1; start < end; stored++ {
+		pageResults, err := s.ReadPage(entry, stored)
+		if err != nil {
+			return nil, err
+		}
+		first := (stored - 1) * entry.PageSize
+		from := start - first
+		to := min(end-first, len(pageResults))
+		if from >= to {
+			break
+		}
+		results = append(results, pageResults[from:to]...)
+		start = first + to
+	}
+
+	return &api.ExecutionResultsPage{
+		Results:    results,
+		TotalCount: entry.TotalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// Touch records that an entry was used, for LRU eviction
+func (s *Store) Touch(id string) error {
+	entry, err := s.Get(id)
+	if err != nil || entry == nil {
+		return err
+	}
+	entry.LastUsed = time.Now()
+	return s.writeMeta(filepath.Join(s.dir, id), entry)
+}
+
+// List returns all cached entries, most recently used first
+func (s *Store) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, d := range dirs {
+		if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		entry, err := s.Get(d.Name())
+		if err != nil || entry == nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Size returns the total size of the cached entries
+func (s *Store) Size() (int64, error) {
+	entries, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	return total, nil
+}
+
+// Remove deletes a cached execution
+func (s *Store) Remove(id string) error {
+	dir, err := s.entryDir(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// Clear deletes every cached execution of this server
+func (s *Store) Clear() error {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if err := os.RemoveAll(filepath.Join(s.dir, d.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune evicts least recently used entries until the cache fits in maxSize
+// (0 means no size limit) and removes entries unused for longer than olderThan
+// (0 means no age limit). It also cleans up abandoned partial writes.
+func (s *Store) Prune(maxSize int64, olderThan time.Duration) ([]Entry, error) {
+	return s.prune(maxSize, olderThan, "")
+}
+
+// prune implements Prune, never evicting the entry keep
+func (s *Store) prune(maxSize int64, olderThan time.Duration, keep string) ([]Entry, error) {
+	s.removeStalePartials()
+
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	var removed []Entry
+	now := time.Now()
+
+	// Entries are most recently used first; evict from the end
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		tooOld := olderThan > 0 && now.Sub(e.LastUsed) > olderThan
+		tooBig := maxSize > 0 && total > maxSize
+		if (!tooOld && !tooBig) || e.ExecutionID == keep {
+			continue
+		}
+		if err := s.Remove(e.ExecutionID); err != nil {
+			return removed, err
+		}
+		total -= e.Size
+		removed = append(removed, e)
+	}
+
+	return removed, nil
+}
+
+// removeStalePartials deletes unfinished writes left behind by interrupted runs
+func (s *Store) removeStalePartials() {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, d := range dirs {
+		if !strings.HasPrefix(d.Name(), partialPrefix) {
+			continue
+		}
+		info, err := d.Info()
+		if err == nil && time.Since(info.ModTime()) > partialMaxAge {
+			os.RemoveAll(filepath.Join(s.dir, d.Name()))
+		}
+	}
+}
+
+// writeMeta atomically writes an entry's meta.json
+func (s *Store) writeMeta(dir string, entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, metaFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, metaFile))
+}
+
+// pageFile returns the file name of a stored page
+func pageFile(page int) string {
+	return fmt.Sprintf("page-%05d.ndjson.gz", page)
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// openTestStore returns an empty store under a temporary home directory
+func openTestStore(t *testing.T, maxSize int64) *Store {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	store, err := Open("https://binmave.example", "alice", maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// writeEntry caches an execution of total results stored in pages of pageSize,
+// with result IDs 0 to total-1
+func writeEntry(t *testing.T, store *Store, id string, pageSize, total int) *Entry {
+	t.Helper()
+	w, err := store.NewWriter(id, pageSize, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for page := 1; page <= w.Pages(); page++ {
+		var results []api.ExecutionResult
+		for i := (page - 1) * pageSize; i < min(page*pageSize, total); i++ {
+			results = append(results, api.ExecutionResult{ResultID: i, AgentName: fmt.Sprintf("agent-%d", i)})
+		}
+		if err := w.WritePage(page, results); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Commit(api.Execution{ExecutionID: id}, api.ExecutionStatus{}); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := store.Get(id)
+	if err != nil || entry == nil {
+		t.Fatalf("Get(%s) = %v, %v", id, entry, err)
+	}
+	return entry
+}
+
+func TestResultsPagination(t *testing.T) {
+	store := openTestStore(t, 0)
+	entry := writeEntry(t, store, "exec-1", 3, 7)
+
+	tests := []struct {
+		name           string
+		page, pageSize int
+		want           []int
+	}{
+		{"first stored page", 1, 3, []int{0, 1, 2}},
+		{"partial last page", 3, 3, []int{6}},
+		{"beyond the end", 4, 3, nil},
+		{"far beyond the end", 100, 3, nil},
+		{"across stored pages", 2, 4, []int{4, 5, 6}},
+		{"larger than stored", 1, 5, []int{0, 1, 2, 3, 4}},
+		{"everything", 1, 100, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"smaller than stored", 4, 2, []int{6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := store.Results(entry, tt.page, tt.pageSize)
+			if err != nil {
+				t.Fatalf("Results: %v", err)
+			}
+			if page.TotalCount != 7 {
+				t.Errorf("total = %d, want 7", page.TotalCount)
+			}
+			var got []int
+			for _, r := range page.Results {
+				got = append(got, r.ResultID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("page %d of %d = %v, want %v", tt.page, tt.pageSize, got, tt.want)
+			}
+		})
+	}
+}
+
+// setLastUsed backdates an entry for eviction order
+func setLastUsed(t *testing.T, store *Store, id string, at time.Time) {
+	t.Helper()
+	entry, err := store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.LastUsed = at
+	if err := store.writeMeta(filepath.Join(store.Dir(), id), entry); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cachedIDs(t *testing.T, store *Store) []string {
+	t.Helper()
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ExecutionID)
+	}
+	return ids
+}
+
+func TestPruneBySizeEvictsLeastRecentlyUsed(t *testing.T) {
+	store := openTestStore(t, 0)
+	now := time.Now()
+	var size int64
+	for i, id := range []string{"old", "mid", "new"} {
+		size = writeEntry(t, store, id, 10, 10).Size
+		setLastUsed(t, store, id, now.Add(time.Duration(i-3)*time.Hour))
+	}
+
+	// Room for two entries of about the same size
+	removed, err := store.Prune(2*size+size/2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].ExecutionID != "old" {
+		t.Errorf("removed %v, want old", removed)
+	}
+	if got := fmt.Sprint(cachedIDs(t, store)); got != "[new mid]" {
+		t.Errorf("cached = %s, want [new mid]", got)
+	}
+	total, err := store.Size()
+	if err != nil || total > 2*size+size/2 {
+		t.Errorf("size = %d, %v after pruning to %d", total, err, 2*size+size/2)
+	}
+}
+
+func TestPruneByAge(t *testing.T) {
+	store := openTestStore(t, 0)
+	writeEntry(t, store, "stale", 10, 5)
+	writeEntry(t, store, "fresh", 10, 5)
+	setLastUsed(t, store, "stale", time.Now().Add(-48*time.Hour))
+
+	removed, err := store.Prune(0, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].ExecutionID != "stale" {
+		t.Errorf("removed %v, want stale", removed)
+	}
+	if got := fmt.Sprint(cachedIDs(t, store)); got != "[fresh]" {
+		t.Errorf("cached = %s, want [fresh]", got)
+	}
+}
+
+func TestCommitKeepsEntryLargerThanLimit(t *testing.T) {
+	store := openTestStore(t, 1)
+	writeEntry(t, store, "first", 10, 10)
+	writeEntry(t, store, "second", 10, 10)
+
+	// Each commit evicts the others but never the entry it wrote
+	if got := fmt.Sprint(cachedIDs(t, store)); got != "[second]" {
+		t.Errorf("cached = %s, want [second]", got)
+	}
+}
+
+func TestPruneKeepsWritesInProgress(t *testing.T) {
+	store := openTestStore(t, 0)
+	w, err := store.NewWriter("writing", 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePage(1, []api.ExecutionResult{{ResultID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Prune(1, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Commit(api.Execution{ExecutionID: "writing"}, api.ExecutionStatus{}); err != nil {
+		t.Fatalf("Commit after Prune: %v", err)
+	}
+	if entry, err := store.Get("writing"); err != nil || entry == nil {
+		t.Errorf("Get = %v, %v", entry, err)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// ErrOffline is returned in offline mode for data that is not in the cache
+var ErrOffline = errors.New("offline")
+
+// NewOfflineBackend serves cached executions only; everything else fails with ErrOffline
+func NewOfflineBackend(store *Store) *Backend {
+	b := NewBackend(offlineUpstream{}, store)
+	b.offline = true
+	return b
+}
+
+// Offline reports whether the backend works from the cache without a server
+func (b *Backend) Offline() bool {
+	return b.offline
+}
+
+// CachedAt returns when an execution was cached, if it is served from the cache
+func (b *Backend) CachedAt(id string) (time.Time, bool) {
+	entry := b.Cached(id)
+	if entry == nil {
+		return time.Time{}, false
+	}
+	return entry.CachedAt, true
+}
+
+// offlineUpstream is the api.Backend used in offline mode
+type offlineUpstream struct{}
+
+func notCached(id string) error {
+	return fmt.Errorf("%w: execution %s is not in the local cache", ErrOffline, id)
+}
+
+func (offlineUpstream) ListAgents(ctx context.Context) ([]api.Agent, error) {
+	return nil, fmt.Errorf("%w: agents are not cached", ErrOffline)
+}
+
+func (offlineUpstream) GetAgentStats(ctx context.Context) (*api.AgentStats, error) {
+	return nil, fmt.Errorf("%w: agent statistics are not cached", ErrOffline)
+}
+
+func (offlineUpstream) ListScripts(ctx context.Context) ([]api.Script, error) {
+	return nil, fmt.Errorf("%w: scripts are not cached", ErrOffline)
+}
+
+func (offlineUpstream) GetScript(ctx context.Context, id int) (*api.Script, error) {
+	return nil, fmt.Errorf("%w: scripts are not cached", ErrOffline)
+}
+
+func (offlineUpstream) ExecuteScript(ctx context.Context, scriptID int, req api.ExecuteRequest) (*api.ExecuteResponse, error) {
+	return nil, fmt.Errorf("%w: cannot execute scripts", ErrOffline)
+}
+
+func (offlineUpstream) ListRecentExecutions(ctx context.Context, limit int) ([]api.ExecutionListItem, error) {
+	return nil, fmt.Errorf("%w: use 'binmave cache ls' to list cached executions", ErrOffline)
+}
+
+func (offlineUpstream) GetExecution(ctx context.Context, id string) (*api.Execution, error) {
+	return nil, notCached(id)
+}
+
+func (offlineUpstream) GetExecutionStatus(ctx context.Context, id string) (*api.ExecutionStatus, error) {
+	return nil, notCached(id)
+}
+
+func (offlineUpstream) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	return nil, notCached(id)
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// Writer stores the pages of one execution as they arrive, in any order.
+// Pages may be written concurrently. Nothing is visible in the cache until Commit.
+type Writer struct {
+	store      *Store
+	id         string
+	dir        string
+	pageSize   int
+	totalCount int
+
+	mu    sync.Mutex
+	pages map[int]bool
+	size  int64
+}
+
+// NewWriter starts writing an execution with the given page size and total result count
+func (s *Store) NewWriter(id string, pageSize, totalCount int) (*Writer, error) {
+	if _, err := s.entryDir(id); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(s.dir, partialPrefix+id+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		store:      s,
+		id:         id,
+		dir:        dir,
+		pageSize:   pageSize,
+		totalCount: totalCount,
+		pages:      make(map[int]bool),
+	}, nil
+}
+
+// PageSize returns the page size the writer was created with
+func (w *Writer) PageSize() int {
+	return w.pageSize
+}
+
+// TotalCount returns the result count the writer expects
+func (w *Writer) TotalCount() int {
+	return w.totalCount
+}
+
+// Pages returns the number of pages needed to hold every result
+func (w *Writer) Pages() int {
+	return max((w.totalCount+w.pageSize-1)/w.pageSize, 1)
+}
+
+// WritePage stores one page of results
+func (w *Writer) WritePage(page int, results []api.ExecutionResult) error {
+	if page < 1 || page > w.Pages() {
+		return nil
+	}
+	w.mu.Lock()
+	written := w.pages[page]
+	w.mu.Unlock()
+	if written {
+		return nil
+	}
+
+	path := filepath.Join(w.dir, pageFile(page))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	encoder := json.NewEncoder(gz)
+	for _, r := range results {
+		if err := encoder.Encode(r); err != nil {
+			gz.Close()
+			f.Close()
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.pages[page] && err == nil {
+		w.size += info.Size()
+	}
+	w.pages[page] = true
+	return nil
+}
+
+// Complete reports whether every page has been written
+func (w *Writer) Complete() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pages) == w.Pages()
+}
+
+// Commit publishes the written pages as a cache entry, replacing any existing
+// one, and evicts old entries if the cache exceeds its size limit
+func (w *Writer) Commit(execution api.Execution, status api.ExecutionStatus) error {
+	w.mu.Lock()
+	size := w.size
+	w.mu.Unlock()
+
+	now := time.Now()
+	entry := &Entry{
+		ExecutionID: w.id,
+		Server:      w.store.server,
+		Profile:     w.store.profile,
+		Execution:   execution,
+		Status:      status,
+		TotalCount:  w.totalCount,
+		PageSize:    w.pageSize,
+		Pages:       w.Pages(),
+		Size:        size,
+		CachedAt:    now,
+		LastUsed:    now,
+	}
+
+	if err := w.store.writeMeta(w.dir, entry); err != nil {
+		w.Abort()
+		return err
+	}
+
+	final := filepath.Join(w.store.dir, w.id)
+	os.RemoveAll(final)
+	if err := os.Rename(w.dir, final); err != nil {
+		w.Abort()
+		return err
+	}
+
+	// The entry just written is kept even if it alone exceeds the limit
+	_, err := w.store.prune(w.store.maxSize, 0, w.id)
+	return err
+}
+
+// Abort discards everything written
+func (w *Writer) Abort() {
+	os.RemoveAll(w.dir)
+}
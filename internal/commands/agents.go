@@ -2,15 +2,13 @@ package commands
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/output"
 )
 
 var agentsCmd = &cobra.Command{
@@ -20,17 +18,19 @@ var agentsCmd = &cobra.Command{
 }
 
 var agentsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all agents",
-	Long:  `Display a list of all registered agents with their status.`,
-	RunE:  runAgentsList,
+	Use:         "list",
+	Short:       "List all agents",
+	Long:        `Display a list of all registered agents with their status.`,
+	Annotations: requires(auth.ViewAgents),
+	RunE:        runAgentsList,
 }
 
 var agentsStatsCmd = &cobra.Command{
-	Use:   "stats",
-	Short: "Show agent statistics",
-	Long:  `Display statistics about agents including online/offline counts.`,
-	RunE:  runAgentsStats,
+	Use:         "stats",
+	Short:       "Show agent statistics",
+	Long:        `Display statistics about agents including online/offline counts.`,
+	Annotations: requires(auth.ViewAgents),
+	RunE:        runAgentsStats,
 }
 
 func init() {
@@ -42,7 +42,7 @@ func init() {
 }
 
 func runAgentsList(cmd *cobra.Command, args []string) error {
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -52,11 +52,11 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 
 	agents, err := client.ListAgents(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list agents: %w", err)
+		return apiError(err, "list agents")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(agents)
+	if isMachineOutput() {
+		return printOutput(agents, nil)
 	}
 
 	if len(agents) == 0 {
@@ -64,26 +64,41 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "STATUS\tNAME\tOS\tVERSION\tLAST SEEN\tIP")
-	fmt.Fprintln(w, "------\t----\t--\t-------\t---------\t--")
+	table := output.NewTable(
+		output.Column{Header: "STATUS"},
+		output.Column{Header: "NAME"},
+		output.Column{Header: "OS"},
+		output.Column{Header: "VERSION"},
+		output.Column{Header: "LAST SEEN"},
+		output.Column{Header: "IP"},
+		output.Column{Header: "ID", Wide: true},
+		output.Column{Header: "TRANSPORT", Wide: true},
+		output.Column{Header: "CONFIG", Wide: true},
+	)
 
 	for _, agent := range agents {
 		status := formatAgentStatus(agent.AgentStatus)
 		lastSeen := formatTimeAgo(agent.LastConnectionEstablished)
-		os := truncateString(agent.OperatingSystem, 25)
+		os := agent.OperatingSystem
+		if !isWideOutput() {
+			os = truncateString(os, 25)
+		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		table.AddRow(
 			status,
 			agent.MachineName,
 			os,
 			agent.AgentVersion,
 			lastSeen,
 			agent.LastIP,
+			agent.AgentID,
+			agent.LastTransportType,
+			agent.AgentConfigName,
 		)
 	}
-	w.Flush()
+	if err := printOutput(agents, table); err != nil {
+		return err
+	}
 
 	fmt.Printf("\nTotal: %d agents\n", len(agents))
 
@@ -91,7 +106,7 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 }
 
 func runAgentsStats(cmd *cobra.Command, args []string) error {
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -101,11 +116,11 @@ func runAgentsStats(cmd *cobra.Command, args []string) error {
 
 	stats, err := client.GetAgentStats(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get agent stats: %w", err)
+		return apiError(err, "get agent stats")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(stats)
+	if isMachineOutput() {
+		return printOutput(stats, nil)
 	}
 
 	fmt.Println("Agent Statistics")
@@ -167,9 +182,3 @@ func truncateString(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
-
-func printJSON(v interface{}) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(v)
-}
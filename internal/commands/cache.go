@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/cache"
+	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/output"
+)
+
+var (
+	cachePruneMaxSize   string
+	cachePruneOlderThan time.Duration
+	cacheClearAll       bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local result cache",
+	Long: `Manage the on-disk cache of completed executions.
+
+Results of completed executions are cached under ~/.binmave/cache (one
+directory per server and signed-in account) the first time they are
+downloaded, so opening them again with 'results', 'compare' or
+'executions results' is instant.
+The cache is limited by the cache_max_size setting (default 1GB); least
+recently used executions are evicted first. Use --no-cache to bypass it.`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List cached executions",
+	RunE:    runCacheLs,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Evict cached executions over the size limit",
+	Long: `Evict least recently used executions until the cache fits its size limit.
+
+Examples:
+  # Apply the configured cache_max_size
+  binmave cache prune
+
+  # Shrink to 200MB and drop anything unused for a week
+  binmave cache prune --max-size 200MB --older-than 168h`,
+	RunE: runCachePrune,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached executions",
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cachePruneCmd.Flags().StringVar(&cachePruneMaxSize, "max-size", "", "Size limit to prune to (default: cache_max_size setting)")
+	cachePruneCmd.Flags().DurationVar(&cachePruneOlderThan, "older-than", 0, "Also remove executions not used for this long (e.g. 72h)")
+	cacheClearCmd.Flags().BoolVar(&cacheClearAll, "all", false, "Clear the caches of all servers and accounts, not just the current one")
+
+	// Make 'cache' without subcommand run 'cache ls'
+	cacheCmd.RunE = runCacheLs
+}
+
+// openCache opens the cache of the configured server and signed-in account
+// with the configured size limit
+func openCache() (*cache.Store, error) {
+	maxSize, err := cache.ParseSize(config.Get().CacheMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache_max_size: %w", err)
+	}
+	return cache.Open(config.GetServer(), cacheProfile(), maxSize)
+}
+
+// cacheProfile names the signed-in account from the stored token, so that
+// accounts sharing a server do not share cached results. The token may have
+// expired; it only has to identify the account. Without a token, the account
+// last signed in to the server is used, so --offline still works after logout.
+func cacheProfile() string {
+	server := config.GetServer()
+	profile := tokenProfile()
+	if profile == "" {
+		return cache.LastProfile(server)
+	}
+	cache.RememberProfile(server, profile)
+	return profile
+}
+
+// tokenProfile returns the account named by the stored token, or ""
+func tokenProfile() string {
+	token, err := auth.LoadToken()
+	if err != nil || token == nil {
+		return ""
+	}
+	claims, err := auth.ParseClaims(token.AccessToken)
+	if err != nil || claims.Subject == "" {
+		return ""
+	}
+	if claims.Tenant != "" {
+		return claims.Tenant + "-" + claims.Subject
+	}
+	return claims.Subject
+}
+
+func runCacheLs(cmd *cobra.Command, args []string) error {
+	store, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	if isMachineOutput() {
+		if entries == nil {
+			entries = []cache.Entry{}
+		}
+		return printOutput(entries, nil)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("Cache is empty (%s)\n", store.Dir())
+		return nil
+	}
+
+	table := output.NewTable(
+		output.Column{Header: "EXECUTION ID"},
+		output.Column{Header: "SCRIPT"},
+		output.Column{Header: "RESULTS"},
+		output.Column{Header: "SIZE"},
+		output.Column{Header: "CACHED"},
+		output.Column{Header: "LAST USED"},
+		output.Column{Header: "STATE", Wide: true},
+		output.Column{Header: "PAGES", Wide: true},
+	)
+
+	var total int64
+	for _, e := range entries {
+		table.AddRow(
+			e.ExecutionID,
+			truncateString(e.Execution.ScriptName, 30),
+			strconv.Itoa(e.TotalCount),
+			cache.FormatSize(e.Size),
+			formatTimeAgo(e.CachedAt),
+			formatTimeAgo(e.LastUsed),
+			e.Status.State,
+			strconv.Itoa(e.Pages),
+		)
+		total += e.Size
+	}
+	if err := printOutput(entries, table); err != nil {
+		return err
+	}
+
+	limit := "unlimited"
+	if store.MaxSize() > 0 {
+		limit = cache.FormatSize(store.MaxSize())
+	}
+	fmt.Printf("\nTotal: %d executions, %s of %s (%s)\n", len(entries), cache.FormatSize(total), limit, store.Dir())
+
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	store, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	maxSize := store.MaxSize()
+	if cachePruneMaxSize != "" {
+		if maxSize, err = cache.ParseSize(cachePruneMaxSize); err != nil {
+			return err
+		}
+	}
+
+	removed, err := store.Prune(maxSize, cachePruneOlderThan)
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+
+	if isMachineOutput() {
+		if removed == nil {
+			removed = []cache.Entry{}
+		}
+		return printOutput(removed, nil)
+	}
+
+	var freed int64
+	for _, e := range removed {
+		freed += e.Size
+		fmt.Printf("Removed %s (%s, %s)\n", e.ExecutionID, e.Execution.ScriptName, cache.FormatSize(e.Size))
+	}
+	fmt.Printf("Pruned %d executions, freed %s\n", len(removed), cache.FormatSize(freed))
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	if cacheClearAll {
+		root, err := cache.Root()
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(root); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		fmt.Printf("Cleared all caches (%s)\n", root)
+		return nil
+	}
+
+	store, err := openCache()
+	if err != nil {
+		return err
+	}
+	if err := store.Clear(); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	fmt.Printf("Cleared cache for %s (%s)\n", config.GetServer(), store.Profile())
+
+	return nil
+}
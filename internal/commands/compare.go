@@ -5,7 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
 	"github.com/Binmave/binmave-cli/internal/ui/models"
 )
 
@@ -31,8 +31,9 @@ Examples:
 
   # Using short flag
   binmave compare a1b2c3d4 -b b5c6d7e8`,
-	Args: cobra.ExactArgs(1),
-	RunE: runCompare,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runCompare,
 }
 
 var compareBaselineID string
@@ -40,6 +41,7 @@ var compareBaselineID string
 func init() {
 	compareCmd.Flags().StringVarP(&compareBaselineID, "baseline", "b", "", "Baseline execution ID to compare against (required)")
 	compareCmd.MarkFlagRequired("baseline")
+	addOfflineFlag(compareCmd)
 }
 
 func runCompare(cmd *cobra.Command, args []string) error {
@@ -50,7 +52,7 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create API client
-	client, err := api.NewClient()
+	client, err := newCachedBackend()
 	if err != nil {
 		return err
 	}
@@ -58,12 +60,12 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	// Validate both executions exist
 	_, err = client.GetExecution(cmd.Context(), executionID)
 	if err != nil {
-		return fmt.Errorf("failed to get execution: %w", err)
+		return apiError(err, "get execution")
 	}
 
 	_, err = client.GetExecution(cmd.Context(), compareBaselineID)
 	if err != nil {
-		return fmt.Errorf("failed to get baseline execution: %w", err)
+		return apiError(err, "get baseline execution")
 	}
 
 	// Create TUI model
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/cache"
+)
+
+// Exit codes returned by the binmave binary, one per class of failure
+const (
+	ExitOK          = 0
+	ExitError       = 1 // Unclassified failure
+	ExitAuth        = 3 // Not logged in or session rejected (401)
+	ExitForbidden   = 4 // Role or scope does not allow the operation (403)
+	ExitNotFound    = 5 // Resource does not exist (404)
+	ExitRateLimited = 6 // Server is throttling requests (429)
+	ExitServer      = 7 // Server-side failure (5xx)
+	ExitNetwork     = 8 // Server unreachable or request timed out
+	ExitInvalid     = 9 // Request rejected as invalid (400/409/422)
+)
+
+// commandError carries a friendly message while keeping the underlying error
+// available to errors.Is/As for exit code classification
+type commandError struct {
+	message string
+	err     error
+}
+
+func (e *commandError) Error() string { return e.message }
+func (e *commandError) Unwrap() error { return e.err }
+
+// apiError wraps an API error for display with a message tailored to its class
+func apiError(err error, action string) error {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	var message string
+	switch {
+	case api.IsForbidden(err):
+		role := "(unknown)"
+		if identity := currentIdentity(); identity != nil {
+			role = identity.Role()
+		}
+		message = fmt.Sprintf("your role %s cannot %s: access denied by the server", role, action)
+	case api.IsUnauthorized(err):
+		message = fmt.Sprintf("failed to %s: your session was rejected. Run 'binmave login' again", action)
+	case api.IsNotFound(err):
+		message = fmt.Sprintf("failed to %s: not found", action)
+	case api.IsRateLimited(err):
+		message = fmt.Sprintf("failed to %s: the server is rate limiting requests, try again later", action)
+	case api.IsServerError(err):
+		message = fmt.Sprintf("failed to %s: server error (status %d)", action, apiErr.StatusCode)
+	default:
+		message = fmt.Sprintf("failed to %s: request rejected (status %d)", action, apiErr.StatusCode)
+	}
+
+	if detail := apiErr.Message(); detail != "" {
+		message += ": " + detail
+	}
+	if ref := apiErr.Reference(); ref != "" {
+		message += fmt.Sprintf(" [ref %s]", ref)
+	}
+
+	return &commandError{message: message, err: err}
+}
+
+// ExitCode maps an error returned by Execute to the process exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	if errors.Is(err, api.ErrNotLoggedIn) {
+		return ExitAuth
+	}
+	if errors.Is(err, errPermissionDenied) {
+		return ExitForbidden
+	}
+
+	switch code := api.StatusCode(err); {
+	case code == 401:
+		return ExitAuth
+	case code == 403:
+		return ExitForbidden
+	case code == 404:
+		return ExitNotFound
+	case code == 429:
+		return ExitRateLimited
+	case code >= 500:
+		return ExitServer
+	case code == 400 || code == 409 || code == 422:
+		return ExitInvalid
+	}
+
+	if isNetworkError(err) || errors.Is(err, cache.ErrOffline) {
+		return ExitNetwork
+	}
+
+	return ExitError
+}
+
+// isNetworkError reports whether err means the server could not be reached in time
+func isNetworkError(err error) bool {
+	if api.StatusCode(err) != 0 {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
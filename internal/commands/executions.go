@@ -3,16 +3,18 @@ package commands
 import (
 	"context"
 	"fmt"
-	"os"
-	"text/tabwriter"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/output"
 )
 
 var (
 	executionsLimit int
+	executionsAll   bool
 )
 
 var executionsCmd = &cobra.Command{
@@ -22,26 +24,33 @@ var executionsCmd = &cobra.Command{
 }
 
 var executionsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List recent executions",
-	Long:  `Display a list of recent script executions.`,
-	RunE:  runExecutionsList,
+	Use:         "list",
+	Short:       "List recent executions",
+	Long:        `Display a list of recent script executions.`,
+	Annotations: requires(auth.ViewExecutions),
+	RunE:        runExecutionsList,
 }
 
 var executionsShowCmd = &cobra.Command{
-	Use:   "show <execution-id>",
-	Short: "Show execution details",
-	Long:  `Display detailed information about a specific execution.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runExecutionsShow,
+	Use:         "show <execution-id>",
+	Short:       "Show execution details",
+	Long:        `Display detailed information about a specific execution.`,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runExecutionsShow,
 }
 
 var executionsResultsCmd = &cobra.Command{
 	Use:   "results <execution-id>",
 	Short: "Show execution results",
-	Long:  `Display results from agents for a specific execution.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runExecutionsResults,
+	Long: `Display results from agents for a specific execution.
+
+By default only the first page of results is shown. Use --all to stream every
+result; pages are fetched ahead while printing, so memory use stays flat
+regardless of fleet size.`,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runExecutionsResults,
 }
 
 func init() {
@@ -50,13 +59,15 @@ func init() {
 	executionsCmd.AddCommand(executionsResultsCmd)
 
 	executionsListCmd.Flags().IntVarP(&executionsLimit, "limit", "n", 20, "Number of executions to show")
+	executionsResultsCmd.Flags().BoolVar(&executionsAll, "all", false, "Stream all results instead of the first page")
+	addOfflineFlag(executionsShowCmd)
 
 	// Make 'executions' without subcommand run 'executions list'
 	executionsCmd.RunE = runExecutionsList
 }
 
 func runExecutionsList(cmd *cobra.Command, args []string) error {
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -66,11 +77,11 @@ func runExecutionsList(cmd *cobra.Command, args []string) error {
 
 	executions, err := client.ListRecentExecutions(ctx, executionsLimit)
 	if err != nil {
-		return fmt.Errorf("failed to list executions: %w", err)
+		return apiError(err, "list executions")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(executions)
+	if isMachineOutput() {
+		return printOutput(executions, nil)
 	}
 
 	if len(executions) == 0 {
@@ -78,10 +89,16 @@ func runExecutionsList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "STATUS\tID\tSCRIPT\tPROGRESS\tCREATED\tBY")
-	fmt.Fprintln(w, "------\t--\t------\t--------\t-------\t--")
+	table := output.NewTable(
+		output.Column{Header: "STATUS"},
+		output.Column{Header: "ID"},
+		output.Column{Header: "SCRIPT"},
+		output.Column{Header: "PROGRESS"},
+		output.Column{Header: "CREATED"},
+		output.Column{Header: "BY"},
+		output.Column{Header: "SCRIPT ID", Wide: true},
+		output.Column{Header: "CREATED AT", Wide: true},
+	)
 
 	for _, exec := range executions {
 		status := formatExecutionStatus(exec.State, exec.Errors)
@@ -90,22 +107,29 @@ func runExecutionsList(cmd *cobra.Command, args []string) error {
 			progress += fmt.Sprintf(" (%d err)", exec.Errors)
 		}
 		created := formatTimeAgo(exec.Created)
-		scriptName := truncateString(exec.ScriptName, 25)
+		scriptName := exec.ScriptName
 		shortID := exec.ExecutionID
-		if len(shortID) > 8 {
-			shortID = shortID[:8]
+		if !isWideOutput() {
+			scriptName = truncateString(scriptName, 25)
+			if len(shortID) > 8 {
+				shortID = shortID[:8]
+			}
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+		table.AddRow(
 			status,
 			shortID,
 			scriptName,
 			progress,
 			created,
 			exec.CreatedBy,
+			strconv.Itoa(exec.ScriptID),
+			exec.Created.Local().Format("2006-01-02 15:04:05"),
 		)
 	}
-	w.Flush()
+	if err := printOutput(executions, table); err != nil {
+		return err
+	}
 
 	fmt.Printf("\nShowing %d most recent executions\n", len(executions))
 
@@ -115,7 +139,7 @@ func runExecutionsList(cmd *cobra.Command, args []string) error {
 func runExecutionsShow(cmd *cobra.Command, args []string) error {
 	executionID := args[0]
 
-	client, err := api.NewClient()
+	client, err := newCachedBackend()
 	if err != nil {
 		return err
 	}
@@ -125,21 +149,32 @@ func runExecutionsShow(cmd *cobra.Command, args []string) error {
 
 	execution, err := client.GetExecution(ctx, executionID)
 	if err != nil {
-		return fmt.Errorf("failed to get execution: %w", err)
+		return apiError(err, "get execution")
 	}
 
 	status, err := client.GetExecutionStatus(ctx, executionID)
 	if err != nil {
-		return fmt.Errorf("failed to get execution status: %w", err)
+		return apiError(err, "get execution status")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(map[string]interface{}{
+	notice := offlineNotice(client, executionID)
+
+	if isMachineOutput() {
+		details := map[string]interface{}{
 			"execution": execution,
 			"status":    status,
-		})
+		}
+		if notice != "" {
+			cachedAt, _ := client.(cacheSource).CachedAt(executionID)
+			details["cachedAt"] = cachedAt
+			details["offline"] = true
+		}
+		return printOutput(details, nil)
 	}
 
+	if notice != "" {
+		fmt.Printf("%s\n\n", notice)
+	}
 	fmt.Printf("Execution Details\n")
 	fmt.Printf("=================\n")
 	fmt.Printf("ID:        %s\n", execution.ExecutionID)
@@ -180,21 +215,29 @@ func runExecutionsShow(cmd *cobra.Command, args []string) error {
 func runExecutionsResults(cmd *cobra.Command, args []string) error {
 	executionID := args[0]
 
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
 
+	if executionsAll {
+		return streamExecutionResults(client, executionID)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	results, err := client.GetExecutionResults(ctx, executionID, 1, 100)
 	if err != nil {
-		return fmt.Errorf("failed to get execution results: %w", err)
+		return apiError(err, "get execution results")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(results)
+	if isMachineOutput() {
+		// Record formats list the results themselves rather than the page
+		if printer.Records() {
+			return printOutput(results.Results, nil)
+		}
+		return printOutput(results, nil)
 	}
 
 	if len(results.Results) == 0 {
@@ -202,38 +245,89 @@ func runExecutionsResults(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "STATUS\tAGENT\tTIME\tRESULT")
-	fmt.Fprintln(w, "------\t-----\t----\t------")
-
+	table := output.NewTable(resultColumns...)
 	for _, result := range results.Results {
-		status := "✓"
-		if result.HasError {
-			status = "✗"
-		}
-		execTime := fmt.Sprintf("%.1fs", float64(result.ExecutionTimeSeconds))
+		table.AddRow(formatResultRow(result)...)
+	}
+	if err := printOutput(results, table); err != nil {
+		return err
+	}
 
-		// Parse result preview
-		resultPreview := truncateString(result.AnswerJSON, 40)
-		if result.HasError && result.RawStdError != "" {
-			resultPreview = truncateString(result.RawStdError, 40)
-		}
+	fmt.Printf("\nShowing %d of %d results\n", len(results.Results), results.TotalCount)
+	if len(results.Results) < results.TotalCount {
+		fmt.Println("Use --all to show every result")
+	}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			status,
-			result.AgentName,
-			execTime,
-			resultPreview,
-		)
+	return nil
+}
+
+// streamExecutionResults prints every result as it arrives, without holding
+// the whole execution in memory
+func streamExecutionResults(client api.Backend, executionID string) error {
+	// Each page request has its own timeout; the whole stream may take longer
+	ctx := context.Background()
+
+	stream := printer.Stream(resultColumns...)
+	for result, err := range api.StreamExecutionResults(ctx, client, executionID) {
+		if err != nil {
+			stream.Close()
+			return apiError(err, "get execution results")
+		}
+		if err := stream.Write(result, formatResultRow(result)...); err != nil {
+			return err
+		}
+	}
+	if err := stream.Close(); err != nil {
+		return err
 	}
-	w.Flush()
 
-	fmt.Printf("\nShowing %d of %d results\n", len(results.Results), results.TotalCount)
+	if isMachineOutput() {
+		return nil
+	}
+	if stream.Count() == 0 {
+		fmt.Println("No results yet.")
+		return nil
+	}
+	fmt.Printf("\nShowing all %d results\n", stream.Count())
 
 	return nil
 }
 
+// resultColumns are the table columns of execution results
+var resultColumns = []output.Column{
+	{Header: "STATUS"},
+	{Header: "AGENT"},
+	{Header: "TIME"},
+	{Header: "RESULT"},
+	{Header: "AGENT ID", Wide: true},
+	{Header: "RECEIVED", Wide: true},
+}
+
+// formatResultRow returns the table row for a result
+func formatResultRow(result api.ExecutionResult) []string {
+	status := "✓"
+	if result.HasError {
+		status = "✗"
+	}
+	execTime := fmt.Sprintf("%.1fs", float64(result.ExecutionTimeSeconds))
+
+	// Parse result preview
+	width := 40
+	if isWideOutput() {
+		width = 100
+	}
+	preview := truncateString(result.AnswerJSON, width)
+	if result.HasError && result.RawStdError != "" {
+		preview = truncateString(result.RawStdError, width)
+	}
+
+	received := ""
+	if !result.ResultReceived.IsZero() {
+		received = result.ResultReceived.Local().Format("2006-01-02 15:04:05")
+	}
+	return []string{status, result.AgentName, execTime, preview, result.AgentID, received}
+}
+
 func formatExecutionStatus(state string, errors int) string {
 	switch state {
 	case "Completed":
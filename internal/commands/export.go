@@ -0,0 +1,393 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/resultset"
+)
+
+// Export formats
+const (
+	exportCSV     = "csv"
+	exportXLSXCSV = "xlsx-compatible-csv"
+	exportNDJSON  = "ndjson"
+	exportJSON    = "json"
+)
+
+var (
+	exportFormat string
+	exportFile   string
+)
+
+var executionsExportCmd = &cobra.Command{
+	Use:   "export <execution-id>",
+	Short: "Export all results of an execution",
+	Long: `Export every result of an execution to a file or stdout.
+
+Each agent's answer is split into one row per item and nested fields are
+flattened with dots, exactly as in the table view of 'binmave results'.
+Every row starts with agent_name, agent_id, item (position in the answer),
+execution_time_seconds, result_received and has_error. In ndjson and json,
+values keep their JSON types and arrays stay arrays; CSV cells are text.
+
+Formats:
+  csv                  Comma-separated values with a header row
+  xlsx-compatible-csv  CSV for Excel: UTF-8 BOM, CRLF line endings, and cells
+                       that would be evaluated as formulas prefixed with '
+  ndjson               One JSON object per row
+  json                 A JSON array of row objects
+
+CSV needs every column up front, so rows are spooled to a temporary file
+before writing; ndjson and json are written as results arrive.
+
+Examples:
+  # Hand results to another team
+  binmave executions export a1b2c3d4 --format xlsx-compatible-csv --file processes.csv
+
+  # Pipe rows into jq
+  binmave executions export a1b2c3d4 --format ndjson | jq 'select(.has_error)'`,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runExecutionsExport,
+}
+
+func init() {
+	executionsCmd.AddCommand(executionsExportCmd)
+
+	executionsExportCmd.Flags().StringVar(&exportFormat, "format", exportCSV, "Export format: csv, xlsx-compatible-csv, ndjson or json")
+	executionsExportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Write to this file instead of stdout")
+	addOfflineFlag(executionsExportCmd)
+}
+
+// exportColumns come before the answer's own columns in every row
+var exportColumns = []string{"agent_name", "agent_id", "item", "execution_time_seconds", "result_received", "has_error"}
+
+// rowWriter writes exported rows in one format
+type rowWriter interface {
+	Write(result api.ExecutionResult, row resultset.Row) error
+	Close() error
+	Discard() int // Drops rows not yet written after a failure, returning how many were written
+}
+
+func runExecutionsExport(cmd *cobra.Command, args []string) error {
+	executionID := args[0]
+
+	format := strings.ToLower(exportFormat)
+	switch format {
+	case exportCSV, exportXLSXCSV, exportNDJSON, exportJSON:
+	default:
+		return fmt.Errorf("unknown export format %q: use csv, xlsx-compatible-csv, ndjson or json", exportFormat)
+	}
+
+	client, err := newCachedBackend()
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if exportFile != "" {
+		f, err := os.Create(exportFile)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	buf := bufio.NewWriter(out)
+
+	rows, err := exportResults(client, executionID, format, buf)
+	if err != nil && exportFile == "" && rows > 0 {
+		// Rows already streamed cannot be taken back; say where the output stops
+		buf.Flush()
+		return &commandError{
+			message: fmt.Sprintf("export incomplete: output truncated after %d rows: %v", rows, err),
+			err:     err,
+		}
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		if exportFile != "" {
+			os.Remove(exportFile)
+		}
+		return err
+	}
+
+	if exportFile != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d rows to %s\n", rows, exportFile)
+	}
+	return nil
+}
+
+// exportResults streams every result of the execution through a row writer
+// and returns the number of rows written, which after a failure are those
+// already streamed to out
+func exportResults(client api.Backend, executionID, format string, out io.Writer) (int, error) {
+	var w rowWriter
+	switch format {
+	case exportCSV, exportXLSXCSV:
+		spool, err := newCSVRowWriter(out, format == exportXLSXCSV)
+		if err != nil {
+			return 0, err
+		}
+		w = spool
+	default:
+		w = &jsonRowWriter{out: out, array: format == exportJSON}
+	}
+
+	// Progress goes to stderr, and only when the data goes to a file
+	var opts []api.FetchOption
+	if exportFile != "" {
+		opts = append(opts, api.WithProgress(func(p api.FetchProgress) {
+			fmt.Fprintf(os.Stderr, "\rFetching results: page %d/%d", p.Page, p.Pages)
+		}))
+		defer fmt.Fprintln(os.Stderr)
+	}
+
+	// Each page request has its own timeout; the whole export may take longer
+	ctx := context.Background()
+
+	count := 0
+	for result, err := range api.StreamExecutionResults(ctx, client, executionID, opts...) {
+		if err != nil {
+			return w.Discard(), apiError(err, "get execution results")
+		}
+		for _, row := range resultset.Rows(result) {
+			if err := w.Write(result, row); err != nil {
+				return w.Discard(), err
+			}
+			count++
+		}
+	}
+	return count, w.Close()
+}
+
+// exportFields returns the values of exportColumns for a row
+func exportFields(result api.ExecutionResult, row resultset.Row) []interface{} {
+	received := ""
+	if !result.ResultReceived.IsZero() {
+		received = result.ResultReceived.UTC().Format(time.RFC3339)
+	}
+	return []interface{}{
+		row.AgentName,
+		row.AgentID,
+		row.Index,
+		result.ExecutionTimeSeconds,
+		received,
+		row.HasError,
+	}
+}
+
+// jsonRowWriter writes rows as JSON objects with the export columns first
+type jsonRowWriter struct {
+	out   io.Writer
+	array bool
+	count int
+}
+
+func (w *jsonRowWriter) Write(result api.ExecutionResult, row resultset.Row) error {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, value := range exportFields(result, row) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeJSONField(&b, exportColumns[i], value)
+	}
+	for _, column := range rowColumns(row) {
+		b.WriteByte(',')
+		writeJSONField(&b, column, row.Data[column].Interface())
+	}
+	b.WriteByte('}')
+
+	prefix := ""
+	if w.array {
+		prefix = ",\n"
+		if w.count == 0 {
+			prefix = "[\n"
+		}
+	}
+	w.count++
+
+	_, err := fmt.Fprintf(w.out, "%s%s", prefix, b.String())
+	if err == nil && !w.array {
+		_, err = io.WriteString(w.out, "\n")
+	}
+	return err
+}
+
+// Discard keeps the rows written so far; they are written as they arrive
+func (w *jsonRowWriter) Discard() int {
+	return w.count
+}
+
+func (w *jsonRowWriter) Close() error {
+	if !w.array {
+		return nil
+	}
+	if w.count == 0 {
+		_, err := io.WriteString(w.out, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(w.out, "\n]\n")
+	return err
+}
+
+// writeJSONField appends "key":value; encoding a decoded JSON value cannot fail
+func writeJSONField(b *strings.Builder, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	b.Write(k)
+	b.WriteByte(':')
+	b.Write(v)
+}
+
+// rowColumns returns the row's own columns in table view order
+func rowColumns(row resultset.Row) []string {
+	set := make(map[string]bool, len(row.Data))
+	for column := range row.Data {
+		set[column] = true
+	}
+	return resultset.SortColumns(set)
+}
+
+// csvRowWriter spools rows to a temporary file until every column is known
+type csvRowWriter struct {
+	out     io.Writer
+	xlsx    bool
+	spool   *os.File
+	encoder *json.Encoder
+	columns map[string]bool
+}
+
+// spooledRow is a row as stored in the spool file
+type spooledRow struct {
+	Fields []string          `json:"f"`
+	Data   map[string]string `json:"d"`
+}
+
+func newCSVRowWriter(out io.Writer, xlsx bool) (*csvRowWriter, error) {
+	spool, err := os.CreateTemp("", "binmave-export-*.ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	return &csvRowWriter{
+		out:     out,
+		xlsx:    xlsx,
+		spool:   spool,
+		encoder: json.NewEncoder(spool),
+		columns: make(map[string]bool),
+	}, nil
+}
+
+func (w *csvRowWriter) Write(result api.ExecutionResult, row resultset.Row) error {
+	fields := exportFields(result, row)
+	spooled := spooledRow{Fields: make([]string, len(fields)), Data: make(map[string]string, len(row.Data))}
+	for i, value := range fields {
+		spooled.Fields[i] = fmt.Sprint(value)
+	}
+	for column, value := range row.Data {
+		spooled.Data[column] = value.String()
+	}
+	if w.xlsx && !result.ResultReceived.IsZero() {
+		// Excel recognises this layout as a date and time
+		spooled.Fields[4] = result.ResultReceived.UTC().Format("2006-01-02 15:04:05")
+	}
+
+	for column := range row.Data {
+		w.columns[column] = true
+	}
+	return w.encoder.Encode(spooled)
+}
+
+// Close writes the header and the spooled rows, then removes the spool file
+func (w *csvRowWriter) Close() error {
+	defer os.Remove(w.spool.Name())
+	defer w.spool.Close()
+
+	if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	columns := resultset.SortColumns(w.columns)
+
+	if w.xlsx {
+		// A byte order mark makes Excel read the file as UTF-8
+		if _, err := io.WriteString(w.out, "\uFEFF"); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w.out)
+	cw.UseCRLF = w.xlsx
+
+	header := append(append([]string{}, exportColumns...), columns...)
+	if err := cw.Write(w.escape(header)); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(w.spool))
+	record := make([]string, len(header))
+	for decoder.More() {
+		var row spooledRow
+		if err := decoder.Decode(&row); err != nil {
+			return fmt.Errorf("failed to read spool file: %w", err)
+		}
+		copy(record, row.Fields)
+		for i, column := range columns {
+			record[len(exportColumns)+i] = row.Data[column]
+		}
+		if err := cw.Write(w.escape(record)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Discard removes the spool file without writing anything
+func (w *csvRowWriter) Discard() int {
+	w.spool.Close()
+	os.Remove(w.spool.Name())
+	return 0
+}
+
+// escape protects xlsx-compatible cells from being evaluated as formulas
+func (w *csvRowWriter) escape(record []string) []string {
+	if !w.xlsx {
+		return record
+	}
+	for i, value := range record {
+		record[i] = escapeFormula(value)
+	}
+	return record
+}
+
+// escapeFormula prefixes cells that a spreadsheet would treat as a formula
+// with a quote; plain numbers such as -5 are left alone
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/api/apitest"
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"a=b", "a=b"},
+		{"-5", "-5"},
+		{"-1.25", "-1.25"},
+		{"+42", "+42"},
+		{"-1e3", "-1e3"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.value); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// failingPages fails every results page after the first
+type failingPages struct {
+	api.Backend
+}
+
+func (b failingPages) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	if page > 1 {
+		return nil, errors.New("connection lost")
+	}
+	return b.Backend.GetExecutionResults(ctx, id, page, pageSize)
+}
+
+func TestExportToStdoutReportsTruncation(t *testing.T) {
+	cfg := config.Get()
+	pageSize := cfg.PageSize
+	cfg.PageSize = 1
+	saved := newBackend
+	newBackend = func() (api.Backend, error) { return failingPages{apitest.MustFixtures()}, nil }
+	noCache, exportFormat, exportFile = true, exportNDJSON, ""
+	t.Cleanup(func() {
+		cfg.PageSize = pageSize
+		newBackend = saved
+		noCache, exportFormat = false, ""
+	})
+
+	// Capture what reaches stdout before the failure
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = runExecutionsExport(executionsExportCmd, []string{apitest.ProcessesExecutionID})
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if err == nil {
+		t.Fatal("export succeeded although a page failed")
+	}
+	if !strings.Contains(err.Error(), "truncated after 3 rows") {
+		t.Errorf("error = %v, want a truncation notice", err)
+	}
+	if ExitCode(err) == ExitOK {
+		t.Error("exit code is 0")
+	}
+	if lines := strings.Count(string(out), "\n"); lines != 3 {
+		t.Errorf("stdout has %d rows, want the 3 rows of the first result:\n%s", lines, out)
+	}
+}
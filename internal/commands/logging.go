@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// debugEnv enables debug logging when set to a true value or a level name
+const debugEnv = "BINMAVE_DEBUG"
+
+var (
+	debugFlag       bool
+	debugBodiesFlag bool
+	logFileFlag     string
+	logFormatFlag   string
+	logLevelFlag    string
+
+	// logger receives diagnostic output; nil when logging is disabled
+	logger *slog.Logger
+)
+
+// setupLogging builds the logger from the logging flags and BINMAVE_DEBUG.
+// Logging is enabled by --debug, --log-file or BINMAVE_DEBUG.
+func setupLogging() error {
+	level := slog.LevelDebug
+	enabled := debugFlag || logFileFlag != "" || debugBodiesFlag
+
+	if env := os.Getenv(debugEnv); env != "" {
+		if on, err := strconv.ParseBool(env); err == nil {
+			enabled = enabled || on
+		} else if err := level.UnmarshalText([]byte(env)); err == nil {
+			enabled = true
+		} else {
+			return fmt.Errorf("invalid %s value %q: use true, false or a level (debug, info, warn, error)", debugEnv, env)
+		}
+	}
+
+	if logLevelFlag != "" {
+		if err := level.UnmarshalText([]byte(logLevelFlag)); err != nil {
+			return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", logLevelFlag)
+		}
+		enabled = true
+	}
+
+	if !enabled {
+		logger = nil
+		return nil
+	}
+
+	var w io.Writer = os.Stderr
+	if logFileFlag != "" {
+		f, err := os.OpenFile(logFileFlag, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		// The file stays open for the lifetime of the process
+		w = f
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(logFormatFlag) {
+	case "", "text":
+		logger = slog.New(slog.NewTextHandler(w, opts))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(w, opts))
+	default:
+		return fmt.Errorf("invalid --log-format %q: use text or json", logFormatFlag)
+	}
+
+	return nil
+}
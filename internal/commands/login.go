@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/Binmave/binmave-cli/internal/auth"
 	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/output"
 )
 
 var loginCmd = &cobra.Command{
@@ -101,11 +104,61 @@ func runLogout(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var (
+	whoamiVerbose     bool
+	whoamiPermissions bool
+	whoamiCheck       bool
+	whoamiCheckWithin time.Duration
+)
+
 var whoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show information about the current user",
-	Long:  `Display information about the currently authenticated user.`,
-	RunE:  runWhoami,
+	Long: `Display information about the currently authenticated user.
+
+Use --verbose to decode the access token locally and show all roles, scopes,
+tenant, issuer, audience and token lifetime.
+
+Use --permissions to list which CLI operations your roles and token scopes
+allow.
+
+Use --check in scripts as a pre-flight check: it exits non-zero if you are not
+logged in or the session will expire within the --within window and cannot be
+renewed with the refresh token.
+
+Examples:
+  # Show all token claims
+  binmave whoami --verbose
+
+  # List which operations you can perform
+  binmave whoami --permissions
+
+  # Fail if the session will not last another hour
+  binmave whoami --check --within 1h`,
+	RunE: runWhoami,
+}
+
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiVerbose, "verbose", false, "Show all token claims (roles, scopes, tenant, expiry)")
+	whoamiCmd.Flags().BoolVar(&whoamiPermissions, "permissions", false, "List which CLI operations the current identity can perform")
+	whoamiCmd.Flags().BoolVar(&whoamiCheck, "check", false, "Exit non-zero if the session expires within the --within window")
+	whoamiCmd.Flags().DurationVar(&whoamiCheckWithin, "within", 15*time.Minute, "Expiry window used by --check")
+}
+
+// whoamiDetails is the JSON representation of whoami --verbose
+type whoamiDetails struct {
+	User            *auth.UserInfo `json:"user,omitempty"`
+	Server          string         `json:"server"`
+	Subject         string         `json:"subject,omitempty"`
+	Issuer          string         `json:"issuer,omitempty"`
+	Audience        []string       `json:"audience,omitempty"`
+	Tenant          string         `json:"tenant,omitempty"`
+	ClientID        string         `json:"clientId,omitempty"`
+	Roles           []string       `json:"roles"`
+	Scopes          []string       `json:"scopes"`
+	IssuedAt        *time.Time     `json:"issuedAt,omitempty"`
+	ExpiresAt       time.Time      `json:"expiresAt"`
+	HasRefreshToken bool           `json:"hasRefreshToken"`
 }
 
 func runWhoami(cmd *cobra.Command, args []string) error {
@@ -115,17 +168,32 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 	}
 
 	if token == nil {
+		if whoamiCheck {
+			return fmt.Errorf("not logged in. Run 'binmave login' first")
+		}
 		fmt.Println("You are not logged in. Run 'binmave login' to authenticate.")
 		return nil
 	}
 
+	if whoamiCheck {
+		return runWhoamiCheck(token)
+	}
+
 	userInfo, err := auth.GetUserInfo(token)
 	if err != nil {
 		return fmt.Errorf("failed to get user info: %w", err)
 	}
 
-	if IsJSONOutput() {
-		return printJSON(userInfo)
+	if whoamiPermissions {
+		return printWhoamiPermissions(token, userInfo)
+	}
+
+	if whoamiVerbose {
+		return printWhoamiVerbose(token, userInfo)
+	}
+
+	if isMachineOutput() {
+		return printOutput(userInfo, nil)
 	}
 
 	fmt.Printf("User: %s\n", userInfo.GetDisplayName())
@@ -140,3 +208,170 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runWhoamiCheck fails if the session will expire within the check window.
+// An access token about to expire is acceptable as long as it can be refreshed.
+func runWhoamiCheck(token *auth.TokenInfo) error {
+	deadline := time.Now().Add(whoamiCheckWithin)
+
+	if token.ExpiresAt.Before(deadline) {
+		if token.RefreshToken == "" {
+			return fmt.Errorf("session expires at %s (within %s) and has no refresh token. Run 'binmave login'",
+				token.ExpiresAt.Format("2006-01-02 15:04:05"), whoamiCheckWithin)
+		}
+
+		refreshed, err := auth.RefreshAccessToken(token.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("session expires at %s (within %s) and could not be renewed: %w",
+				token.ExpiresAt.Format("2006-01-02 15:04:05"), whoamiCheckWithin, err)
+		}
+		token = refreshed
+
+		if token.ExpiresAt.Before(deadline) {
+			return fmt.Errorf("session was renewed but still expires at %s (within %s). Run 'binmave login'",
+				token.ExpiresAt.Format("2006-01-02 15:04:05"), whoamiCheckWithin)
+		}
+	}
+
+	if !isMachineOutput() {
+		fmt.Printf("✓ Session valid until %s\n", token.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+// printWhoamiVerbose prints the user info together with the decoded token claims
+func printWhoamiVerbose(token *auth.TokenInfo, userInfo *auth.UserInfo) error {
+	details := whoamiDetails{
+		User:            userInfo,
+		Server:          config.GetServer(),
+		Roles:           userInfo.Roles,
+		Scopes:          strings.Fields(token.Scope),
+		ExpiresAt:       token.ExpiresAt,
+		HasRefreshToken: token.RefreshToken != "",
+	}
+
+	claims, claimsErr := auth.ParseClaims(token.AccessToken)
+	if claimsErr == nil {
+		details.Subject = claims.Subject
+		details.Issuer = claims.Issuer
+		details.Audience = claims.Audience
+		details.Tenant = claims.Tenant
+		details.ClientID = claims.ClientID
+		if len(claims.Roles) > 0 {
+			details.Roles = claims.Roles
+		}
+		if len(claims.Scopes) > 0 {
+			details.Scopes = claims.Scopes
+		}
+		if !claims.IssuedAt.IsZero() {
+			details.IssuedAt = &claims.IssuedAt
+		}
+		if !claims.ExpiresAt.IsZero() {
+			details.ExpiresAt = claims.ExpiresAt
+		}
+	}
+
+	if isMachineOutput() {
+		return printOutput(details, nil)
+	}
+
+	fmt.Printf("User:          %s\n", userInfo.GetDisplayName())
+	if userInfo.Email != "" {
+		fmt.Printf("Email:         %s\n", userInfo.Email)
+	}
+	fmt.Printf("Server:        %s\n", details.Server)
+	if details.Subject != "" {
+		fmt.Printf("Subject:       %s\n", details.Subject)
+	}
+	if details.Tenant != "" {
+		fmt.Printf("Tenant:        %s\n", details.Tenant)
+	}
+	if details.Issuer != "" {
+		fmt.Printf("Issuer:        %s\n", details.Issuer)
+	}
+	if len(details.Audience) > 0 {
+		fmt.Printf("Audience:      %s\n", strings.Join(details.Audience, ", "))
+	}
+	if details.ClientID != "" {
+		fmt.Printf("Client:        %s\n", details.ClientID)
+	}
+	fmt.Printf("Roles:         %s\n", joinOrNone(details.Roles))
+	fmt.Printf("Scopes:        %s\n", joinOrNone(details.Scopes))
+	if details.IssuedAt != nil {
+		fmt.Printf("Issued:        %s\n", details.IssuedAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("Expires:       %s (in %s)\n", details.ExpiresAt.Format("2006-01-02 15:04:05"),
+		time.Until(details.ExpiresAt).Round(time.Second))
+	if details.HasRefreshToken {
+		fmt.Printf("Refresh token: present\n")
+	} else {
+		fmt.Printf("Refresh token: none\n")
+	}
+
+	if claimsErr != nil {
+		fmt.Printf("\n⚠ Could not decode access token: %v\n", claimsErr)
+	}
+
+	return nil
+}
+
+// printWhoamiPermissions lists each permission and whether the identity holds it
+func printWhoamiPermissions(token *auth.TokenInfo, userInfo *auth.UserInfo) error {
+	identity := auth.NewIdentity(token)
+	if len(identity.Roles) == 0 {
+		identity.Roles = userInfo.Roles
+	}
+
+	statuses := listPermissions(identity)
+
+	if isMachineOutput() {
+		// Record formats list one permission per line
+		if printer.Records() {
+			return printOutput(statuses, nil)
+		}
+		return printOutput(map[string]interface{}{
+			"roles":       identity.Roles,
+			"scopes":      identity.Scopes,
+			"permissions": statuses,
+		}, nil)
+	}
+
+	fmt.Printf("User:   %s\n", userInfo.GetDisplayName())
+	fmt.Printf("Roles:  %s\n", joinOrNone(identity.Roles))
+	fmt.Printf("Scopes: %s\n\n", joinOrNone(identity.Scopes))
+
+	table := output.NewTable(
+		output.Column{Header: "ALLOWED"},
+		output.Column{Header: "OPERATION"},
+		output.Column{Header: "COMMANDS"},
+		output.Column{Header: "DECIDED BY"},
+		output.Column{Header: "PERMISSION", Wide: true},
+	)
+	for _, status := range statuses {
+		allowed := "✓ yes"
+		if !status.Allowed {
+			allowed = "✗ no"
+		}
+		table.AddRow(allowed, status.Description, strings.Join(status.Commands, ", "), status.DecidedBy, status.Permission)
+	}
+	if err := printOutput(statuses, table); err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if !status.Allowed {
+			fmt.Printf("\nCannot %s: %s\n", status.Description, status.Reason)
+		}
+	}
+
+	fmt.Println("\nPermissions are inferred from your roles, token scopes and permission claims; the server has the final say.")
+
+	return nil
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/cache"
+)
+
+// offlineMode serves executions from the local cache without contacting the server
+var offlineMode bool
+
+// addOfflineFlag registers --offline on a command that can work from cached executions
+func addOfflineFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&offlineMode, "offline", false, "Use cached executions only, without contacting the server")
+}
+
+// newCachedBackend returns the backend for commands that can work from cached
+// executions. With --offline only the local cache is used; otherwise the
+// server is used until a request finds it unreachable, and cached executions
+// are served from then on. An expired login that cannot be renewed because
+// the server is unreachable also falls back to the cache.
+func newCachedBackend() (api.Backend, error) {
+	if offlineMode {
+		return newOfflineBackend()
+	}
+
+	b, err := newBackend()
+	if noCache || recordDir != "" || replayDir != "" {
+		return b, err
+	}
+	if err != nil {
+		// Only an expired login that could not be renewed because the
+		// server is unreachable falls back; a missing login stays an error
+		if !isNetworkError(err) {
+			return nil, err
+		}
+		offline, offlineErr := newOfflineBackend()
+		if offlineErr != nil {
+			return nil, err
+		}
+		reportOffline(err)
+		return offline, nil
+	}
+
+	// Creating a client does not contact the server, so there is nothing
+	// to fall back from yet
+	offline, err := newOfflineBackend()
+	if err != nil {
+		return b, nil
+	}
+	return &fallbackBackend{online: b, offline: offline.(*cache.Backend)}, nil
+}
+
+// newOfflineBackend opens the cache for offline use
+func newOfflineBackend() (api.Backend, error) {
+	if noCache {
+		return nil, fmt.Errorf("--offline cannot be combined with --no-cache")
+	}
+	store, err := openCache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewOfflineBackend(store), nil
+}
+
+// cacheSource is implemented by backends that can serve executions from the local cache
+type cacheSource interface {
+	CachedAt(executionID string) (time.Time, bool)
+	Offline() bool
+}
+
+// offlineNotice describes cached data served offline, or "" when the
+// execution does not come from the cache or the server was reachable
+func offlineNotice(client api.Backend, executionID string) string {
+	source, ok := client.(cacheSource)
+	if !ok || !source.Offline() {
+		return ""
+	}
+	at, ok := source.CachedAt(executionID)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("⚠ OFFLINE - cached %s, may be stale", at.Local().Format("2006-01-02 15:04"))
+}
+
+// fallbackBackend uses the server until a request fails because it cannot be
+// reached, then switches to the local cache for the rest of the command.
+// Script executions are never redirected to the cache.
+type fallbackBackend struct {
+	online  api.Backend
+	offline *cache.Backend
+
+	mu       sync.Mutex
+	fellBack bool
+}
+
+var _ api.Backend = (*fallbackBackend)(nil)
+
+// current returns the backend to send the next request to
+func (b *fallbackBackend) current() api.Backend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fellBack {
+		return b.offline
+	}
+	return b.online
+}
+
+// fallBack switches to the cache after err, reporting it once. It returns
+// false if the error does not mean the server is unreachable.
+func (b *fallbackBackend) fallBack(err error) bool {
+	if !isNetworkError(err) {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.fellBack {
+		b.fellBack = true
+		reportOffline(err)
+	}
+	return true
+}
+
+// reportOffline tells the user that cached executions are served because of err
+func reportOffline(err error) {
+	fmt.Fprintf(os.Stderr, "Server unreachable, working offline from the local cache (%v)\n", err)
+}
+
+// withFallback calls fn on the current backend, and again on the cache if the
+// server turns out to be unreachable
+func withFallback[T any](b *fallbackBackend, fn func(api.Backend) (T, error)) (T, error) {
+	backend := b.current()
+	v, err := fn(backend)
+	if err != nil && backend == b.online && b.fallBack(err) {
+		return fn(b.offline)
+	}
+	return v, err
+}
+
+// Offline reports whether the backend has switched to the cache
+func (b *fallbackBackend) Offline() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.fellBack
+}
+
+// CachedAt returns when an execution was cached, if it is served from the cache
+func (b *fallbackBackend) CachedAt(id string) (time.Time, bool) {
+	if source, ok := b.current().(cacheSource); ok {
+		return source.CachedAt(id)
+	}
+	return time.Time{}, false
+}
+
+func (b *fallbackBackend) ListAgents(ctx context.Context) ([]api.Agent, error) {
+	return withFallback(b, func(c api.Backend) ([]api.Agent, error) { return c.ListAgents(ctx) })
+}
+
+func (b *fallbackBackend) GetAgentStats(ctx context.Context) (*api.AgentStats, error) {
+	return withFallback(b, func(c api.Backend) (*api.AgentStats, error) { return c.GetAgentStats(ctx) })
+}
+
+func (b *fallbackBackend) ListScripts(ctx context.Context) ([]api.Script, error) {
+	return withFallback(b, func(c api.Backend) ([]api.Script, error) { return c.ListScripts(ctx) })
+}
+
+func (b *fallbackBackend) GetScript(ctx context.Context, id int) (*api.Script, error) {
+	return withFallback(b, func(c api.Backend) (*api.Script, error) { return c.GetScript(ctx, id) })
+}
+
+// ExecuteScript is not retried against the cache, so an unclear failure is
+// reported as such
+func (b *fallbackBackend) ExecuteScript(ctx context.Context, scriptID int, req api.ExecuteRequest) (*api.ExecuteResponse, error) {
+	return b.current().ExecuteScript(ctx, scriptID, req)
+}
+
+func (b *fallbackBackend) ListRecentExecutions(ctx context.Context, limit int) ([]api.ExecutionListItem, error) {
+	return withFallback(b, func(c api.Backend) ([]api.ExecutionListItem, error) { return c.ListRecentExecutions(ctx, limit) })
+}
+
+func (b *fallbackBackend) GetExecution(ctx context.Context, id string) (*api.Execution, error) {
+	return withFallback(b, func(c api.Backend) (*api.Execution, error) { return c.GetExecution(ctx, id) })
+}
+
+func (b *fallbackBackend) GetExecutionStatus(ctx context.Context, id string) (*api.ExecutionStatus, error) {
+	return withFallback(b, func(c api.Backend) (*api.ExecutionStatus, error) { return c.GetExecutionStatus(ctx, id) })
+}
+
+func (b *fallbackBackend) GetExecutionResults(ctx context.Context, id string, page, pageSize int) (*api.ExecutionResultsPage, error) {
+	return withFallback(b, func(c api.Backend) (*api.ExecutionResultsPage, error) {
+		return c.GetExecutionResults(ctx, id, page, pageSize)
+	})
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/cache"
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// setupHome points the configuration at a fresh home directory and a server
+// that refuses connections
+func setupHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, config.ConfigDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, config.ConfigFile+".yaml"), []byte("server: http://127.0.0.1:1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expiredToken returns a stored login for subject whose access token has expired
+func expiredToken(subject string) *auth.TokenInfo {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"` + subject + `"}`))
+	return &auth.TokenInfo{
+		AccessToken:  "e30." + payload + ".sig",
+		RefreshToken: "refresh",
+		TokenType:    "Bearer",
+		ExpiresAt:    time.Now().Add(-time.Hour),
+	}
+}
+
+func TestCachedBackendFallsBackWhenRenewalCannotReachServer(t *testing.T) {
+	setupHome(t)
+	if err := auth.SaveToken(expiredToken("alice")); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := newCachedBackend()
+	if err != nil {
+		t.Fatalf("newCachedBackend: %v", err)
+	}
+	source, ok := b.(*cache.Backend)
+	if !ok || !source.Offline() {
+		t.Fatalf("backend = %T, want the offline cache", b)
+	}
+
+	// The account is remembered for use after logout
+	if err := auth.DeleteToken(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cacheProfile(); got != "alice" {
+		t.Errorf("cacheProfile after logout = %q, want alice", got)
+	}
+}
+
+func TestCachedBackendRequiresLoginWhenLoggedOut(t *testing.T) {
+	setupHome(t)
+
+	_, err := newCachedBackend()
+	if err == nil {
+		t.Fatal("newCachedBackend succeeded without a login")
+	}
+	if code := ExitCode(err); code != ExitAuth {
+		t.Errorf("exit code = %d, want %d (%v)", code, ExitAuth, err)
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Binmave/binmave-cli/internal/output"
+)
+
+var (
+	outputFlag string
+
+	// printer writes command output in the format selected with -o/--output
+	printer *output.Printer
+)
+
+// setupOutput builds the printer from -o/--output; --json is an alias for -o json
+func setupOutput() error {
+	spec := outputFlag
+	if jsonOutput {
+		if spec != "" && spec != string(output.FormatJSON) {
+			return fmt.Errorf("--json cannot be combined with --output %s", spec)
+		}
+		spec = string(output.FormatJSON)
+	}
+
+	p, err := output.New(spec, os.Stdout)
+	if err != nil {
+		return err
+	}
+	printer = p
+	return nil
+}
+
+// printOutput prints data in the selected format; table is used for table and wide output
+func printOutput(data interface{}, table *output.Table) error {
+	return printer.Print(data, table)
+}
+
+// isMachineOutput returns true if a format other than table or wide is requested
+func isMachineOutput() bool {
+	return !printer.IsHuman()
+}
+
+// isWideOutput returns true for -o wide
+func isWideOutput() bool {
+	return printer.Format() == output.FormatWide
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/Binmave/binmave-cli/internal/auth"
+)
+
+// permissionAnnotation is the cobra annotation naming the permission a command needs
+const permissionAnnotation = "binmave/permission"
+
+// errPermissionDenied marks errors raised locally because the identity lacks a permission
+var errPermissionDenied = errors.New("permission denied")
+
+// requires returns command annotations declaring the permission a command needs
+func requires(p auth.Permission) map[string]string {
+	return map[string]string{permissionAnnotation: string(p)}
+}
+
+// currentIdentity returns the identity of the stored token without any network
+// calls, or nil if not logged in
+func currentIdentity() *auth.Identity {
+	token, err := auth.LoadToken()
+	if err != nil || token == nil {
+		return nil
+	}
+	return auth.NewIdentity(token)
+}
+
+// requirePermission fails early if the current identity is known to lack the permission
+func requirePermission(p auth.Permission) error {
+	identity := currentIdentity()
+	if identity == nil {
+		return nil
+	}
+	if ok, reason := identity.Can(p); !ok {
+		return &commandError{
+			message: fmt.Sprintf("your role %s cannot %s: %s", identity.Role(), p.Description(), reason),
+			err:     errPermissionDenied,
+		}
+	}
+	return nil
+}
+
+// checkCommandPermission fails early if the command is annotated with a
+// permission the current identity is known to lack
+func checkCommandPermission(cmd *cobra.Command) error {
+	p, ok := cmd.Annotations[permissionAnnotation]
+	if !ok {
+		return nil
+	}
+	return requirePermission(auth.Permission(p))
+}
+
+// permissionStatus describes whether a permission is available to an identity
+type permissionStatus struct {
+	Permission  string   `json:"permission"`
+	Description string   `json:"description"`
+	Allowed     bool     `json:"allowed"`
+	DecidedBy   string   `json:"decidedBy"`
+	Reason      string   `json:"reason,omitempty"`
+	Commands    []string `json:"commands"`
+}
+
+// listPermissions evaluates every permission for the identity and the commands gated by it
+func listPermissions(identity *auth.Identity) []permissionStatus {
+	commandsByPermission := make(map[string][]string)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if p, ok := cmd.Annotations[permissionAnnotation]; ok {
+			commandsByPermission[p] = append(commandsByPermission[p], cmd.CommandPath())
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+
+	var statuses []permissionStatus
+	for _, p := range auth.AllPermissions {
+		decision := identity.Decide(p)
+		statuses = append(statuses, permissionStatus{
+			Permission:  string(p),
+			Description: p.Description(),
+			Allowed:     decision.Allowed,
+			DecidedBy:   decision.By,
+			Reason:      decision.Reason,
+			Commands:    commandsByPermission[string(p)],
+		})
+	}
+	return statuses
+}
@@ -2,10 +2,13 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
-	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/resultset"
 	"github.com/Binmave/binmave-cli/internal/ui/models"
 )
 
@@ -14,21 +17,57 @@ var resultsCmd = &cobra.Command{
 	Short: "View execution results in interactive TUI",
 	Long: `View execution results with multiple view modes.
 
-The interactive TUI provides three view modes:
+The interactive TUI provides four view modes:
   - Table: Flat list of results per agent
   - Tree: Hierarchical data grouped by agent
-  - Aggregated: Merged tree with counts and anomaly detection
+  - Aggregated: Merged tree, or the values of chosen columns stacked, with
+    agent counts, a rarity score and anomaly detection
+  - Group: Rows grouped by columns, with row and agent counts and the
+    min/max/sum of numeric columns
 
 Keyboard shortcuts:
   Tab        Switch between Results/Errors tabs
-  1/2/3      Switch view mode (Table/Tree/Aggregated)
+  1/2/3/4    Switch view mode (Table/Tree/Aggregated/Group)
   Up/Down    Navigate
-  Enter      Expand/collapse nodes (tree views)
+  Left/Right Focus a column, scrolling wide tables (table view)
+  s          Sort by the focused column: ascending, descending, off
+  S          Add the focused column to the current sort
+  c          Pick, hide and reorder columns (table view)
+  Enter      Expand/collapse nodes (tree views), show a group's rows (group view)
+  g          Choose the group-by columns (group view) or the columns to
+             stack (aggregated view)
+  Esc        Back from a group's rows to the group view
   e          Expand all nodes
-  c          Collapse all nodes
+  c          Collapse all nodes (tree views)
   a          Toggle "anomalies only" (aggregated view)
+  o          Toggle rarest first (aggregated view)
+  A          List the agents that have the selected node, with their status;
+             t shows those without it, Enter opens an agent's results
+  x          Mark the agent of the selected row, tree or node (X clears)
+  R          Run a script on the marked agents: pick it, fill in inputs,
+             confirm the targets, then follow the new execution
+  /          Search; Up/Down recall earlier searches
   q          Quit
 
+Stacking:
+  The aggregated view counts the agents that have each node. Flat results,
+  or any results with --stack, are stacked instead: each combination of
+  values of the stacked columns is counted once per agent. A node is an
+  anomaly when it is found on at most --anomaly-threshold agents, given as a
+  count or a percentage of the agents (default 10%). The rarity score rN is
+  log2(agents/count): 0 on every agent, one more each time the share halves.
+
+Search syntax:
+  Searches find the text anywhere in a row, as typed and ignoring case, so
+  C:\Windows, 10.0.0.1:443 or key=value need no quoting. A search starting
+  with ? is a query instead:
+  ?svchost             Any column contains the text
+  ?Name:svchost        The column contains the text (or matches Name:/re/)
+  ?Size>1000000        Compare by type: = != > >= < <= (times: Started>2024-05-01)
+  ?Path~"\\temp\\"     The column matches a regular expression
+  ?/svc.*host/         Any column matches a regular expression
+  not, and, or, ( )    Combine terms; terms next to each other must all match
+
 Examples:
   # View results for an execution
   binmave results a1b2c3d4-e5f6-7890-abcd-ef1234567890
@@ -36,39 +75,98 @@ Examples:
   # Start in tree view
   binmave results a1b2c3d4 --view tree
 
+  # Largest files first, then by name
+  binmave results a1b2c3d4 --sort Size:desc,Name
+
+  # Show only some columns; the choice is remembered for the script
+  binmave results a1b2c3d4 --columns Name,Size,LastWriteTime
+
+  # Which software versions exist, and on how many hosts
+  binmave results a1b2c3d4 --view group --group-by Name,Version
+
   # Start in aggregated view with anomalies filter
-  binmave results a1b2c3d4 --view aggregated --anomalies`,
-	Args: cobra.ExactArgs(1),
-	RunE: runResults,
+  binmave results a1b2c3d4 --view aggregated --anomalies
+
+  # Long tail of autoruns: rarest first, anomalies on at most 2 hosts
+  binmave results a1b2c3d4 --view aggregated --stack Name,Path --anomaly-threshold 2
+
+  # Work from the local cache without contacting the server
+  binmave results a1b2c3d4 --offline`,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runResults,
 }
 
 var (
 	resultsViewMode      string
 	resultsAnomaliesOnly bool
+	resultsSort          string
+	resultsColumns       string
+	resultsGroupBy       string
+	resultsStack         string
+	resultsThreshold     string
+	resultsRarestFirst   bool
 )
 
 func init() {
-	resultsCmd.Flags().StringVarP(&resultsViewMode, "view", "v", "table", "Initial view mode: table, tree, or aggregated")
+	resultsCmd.Flags().StringVarP(&resultsViewMode, "view", "v", "table", "Initial view mode: table, tree, aggregated, or group")
 	resultsCmd.Flags().BoolVarP(&resultsAnomaliesOnly, "anomalies", "a", false, "Show only anomalies (aggregated view)")
+	resultsCmd.Flags().StringVar(&resultsSort, "sort", "", "Sort the table by columns, e.g. Size:desc,Name")
+	resultsCmd.Flags().StringVar(&resultsColumns, "columns", "", "Table columns to show, in order (remembered per script; \"\" shows all)")
+	resultsCmd.Flags().StringVar(&resultsGroupBy, "group-by", "", "Columns to group by in the group view, e.g. Name,Version")
+	resultsCmd.Flags().StringVar(&resultsStack, "stack", "", "Columns to stack in the aggregated view, rarest first, e.g. Name,Path")
+	resultsCmd.Flags().StringVar(&resultsThreshold, "anomaly-threshold", resultset.DefaultThreshold.String(), "Agents at or below which a node is an anomaly: a count such as 3 or a percentage such as 5%")
+	resultsCmd.Flags().BoolVar(&resultsRarestFirst, "rarest-first", false, "Order the aggregated view least common first")
+	addOfflineFlag(resultsCmd)
 }
 
 func runResults(cmd *cobra.Command, args []string) error {
 	executionID := args[0]
 
+	sortKeys, err := resultset.ParseSort(resultsSort)
+	if err != nil {
+		return err
+	}
+	threshold, err := resultset.ParseThreshold(resultsThreshold)
+	if err != nil {
+		return err
+	}
+	stackColumns := splitColumns(resultsStack)
+
 	// Create API client
-	client, err := api.NewClient()
+	client, err := newCachedBackend()
 	if err != nil {
 		return err
 	}
 
 	// Validate execution exists
-	_, err = client.GetExecution(cmd.Context(), executionID)
+	execution, err := client.GetExecution(cmd.Context(), executionID)
 	if err != nil {
-		return fmt.Errorf("failed to get execution: %w", err)
+		return apiError(err, "get execution")
+	}
+
+	// Columns given on the command line replace those remembered for the script
+	columns := config.GetScriptColumns(execution.ScriptID)
+	if cmd.Flags().Changed("columns") {
+		columns = splitColumns(resultsColumns)
+		if err := config.SetScriptColumns(execution.ScriptID, columns); err != nil {
+			return fmt.Errorf("failed to save columns: %w", err)
+		}
 	}
 
 	// Create TUI model
 	model := models.NewResultsModel(executionID, client)
+	model.SetSort(sortKeys)
+	model.SetColumns(columns)
+	model.SetGroupColumns(splitColumns(resultsGroupBy))
+	model.SetStackColumns(stackColumns)
+	model.SetAnomalyThreshold(threshold)
+	model.SetRarestFirst(resultsRarestFirst || len(stackColumns) > 0)
+
+	// Follow-up executions from the TUI need a role that can execute scripts
+	if err := requirePermission(auth.ExecuteScripts); err != nil {
+		model.SetExecuteDenied(err)
+	}
 
 	// Set initial view mode
 	switch resultsViewMode {
@@ -79,13 +177,41 @@ func runResults(cmd *cobra.Command, args []string) error {
 		if resultsAnomaliesOnly {
 			model.SetAnomaliesOnly(true)
 		}
+	case "group":
+		model.SetInitialViewMode(models.GroupView)
 	}
 
 	// Run TUI
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
+	// Remember columns picked in the TUI for the next execution of the script.
+	// The TUI may have moved on to a follow-up execution of another script.
+	shown, ok := final.(*models.ResultsModel)
+	if !ok {
+		return nil
+	}
+	scriptID, loaded := shown.ScriptID()
+	if columns, changed := shown.ColumnLayout(); changed && loaded {
+		if err := config.SetScriptColumns(scriptID, columns); err != nil {
+			return fmt.Errorf("failed to save columns: %w", err)
+		}
+	}
+
 	return nil
 }
+
+// splitColumns parses a comma-separated column list; an empty list means
+// all columns
+func splitColumns(list string) []string {
+	var columns []string
+	for _, col := range strings.Split(list, ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
@@ -5,7 +5,10 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/cache"
 	"github.com/Binmave/binmave-cli/internal/config"
+	"github.com/Binmave/binmave-cli/internal/output"
 )
 
 var (
@@ -26,18 +29,50 @@ Use 'binmave login' to authenticate, then explore agents, scripts, and execution
 			if cmd.Name() == "completion" || cmd.Name() == "__complete" {
 				return nil
 			}
-			return config.Init()
+			if err := config.Init(); err != nil {
+				return err
+			}
+			if err := setupOutput(); err != nil {
+				return err
+			}
+			if err := setupLogging(); err != nil {
+				return err
+			}
+			return checkCommandPermission(cmd)
 		},
 	}
 
 	// Global flags
 	serverFlag string
 	jsonOutput bool
+	recordDir  string
+	replayDir  string
+	noCache    bool
+
+	// newBackend creates the API backend used by commands.
+	// Tests replace it to run commands against a fake or stub server.
+	newBackend = func() (api.Backend, error) {
+		client, err := api.NewClient(clientOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		return withCache(client), nil
+	}
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&serverFlag, "server", "", "Override the server URL")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "Output format: "+output.Formats)
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (same as -o json)")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record all API traffic to cassette files in this directory (secrets redacted)")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay API traffic from cassette files in this directory instead of the network")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the local cache of completed executions")
+	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "Log API traffic (method, URL, status, latency, retries) to stderr; also enabled by BINMAVE_DEBUG")
+	rootCmd.PersistentFlags().BoolVar(&debugBodiesFlag, "debug-bodies", false, "Include redacted, truncated request and response bodies in debug logs")
+	rootCmd.PersistentFlags().StringVar(&logFileFlag, "log-file", "", "Write logs to this file instead of stderr (recommended with interactive views)")
+	rootCmd.PersistentFlags().StringVar(&logFormatFlag, "log-format", "text", "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "", "Minimum log level: debug, info, warn or error (default debug)")
 
 	// Add subcommands
 	rootCmd.AddCommand(loginCmd)
@@ -49,6 +84,7 @@ func init() {
 	rootCmd.AddCommand(watchCmd)
 	rootCmd.AddCommand(resultsCmd)
 	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(cacheCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -61,9 +97,39 @@ func Execute() error {
 	return nil
 }
 
-// IsJSONOutput returns true if JSON output is requested
-func IsJSONOutput() bool {
-	return jsonOutput
+// clientOptions returns API client options derived from the global flags
+func clientOptions() []api.Option {
+	var opts []api.Option
+	if recordDir != "" {
+		opts = append(opts, api.WithRecording(recordDir))
+	}
+	if replayDir != "" {
+		opts = append(opts, api.WithReplay(replayDir))
+	}
+	if logger != nil {
+		opts = append(opts, api.WithLogger(logger))
+		if debugBodiesFlag {
+			opts = append(opts, api.WithBodyLogging(api.DefaultDebugBodyLimit))
+		}
+	}
+	return opts
+}
+
+// withCache wraps a backend with the local result cache unless disabled.
+// Recording and replaying always talk to the (recorded) server directly.
+func withCache(b api.Backend) api.Backend {
+	if noCache || recordDir != "" || replayDir != "" {
+		return b
+	}
+
+	store, err := openCache()
+	if err != nil {
+		if logger != nil {
+			logger.Warn("result cache disabled", "error", err)
+		}
+		return b
+	}
+	return cache.NewBackend(b, store)
 }
 
 var versionCmd = &cobra.Command{
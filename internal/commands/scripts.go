@@ -3,13 +3,14 @@ package commands
 import (
 	"context"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
+	"github.com/Binmave/binmave-cli/internal/output"
 )
 
 var scriptsCmd = &cobra.Command{
@@ -19,23 +20,26 @@ var scriptsCmd = &cobra.Command{
 }
 
 var scriptsListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all scripts",
-	Long:  `Display a list of all available scripts.`,
-	RunE:  runScriptsList,
+	Use:         "list",
+	Short:       "List all scripts",
+	Long:        `Display a list of all available scripts.`,
+	Annotations: requires(auth.ViewScripts),
+	RunE:        runScriptsList,
 }
 
 var scriptsShowCmd = &cobra.Command{
-	Use:   "show <script-id>",
-	Short: "Show script details",
-	Long:  `Display detailed information about a specific script.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runScriptsShow,
+	Use:         "show <script-id>",
+	Short:       "Show script details",
+	Long:        `Display detailed information about a specific script.`,
+	Annotations: requires(auth.ViewScripts),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runScriptsShow,
 }
 
 var (
-	runAgentFilter  string
-	runWithWatch    bool
+	runAgentFilter    string
+	runWithWatch      bool
+	runIdempotencyKey string
 )
 
 var scriptsRunCmd = &cobra.Command{
@@ -51,9 +55,20 @@ Examples:
   binmave scripts run 42 --filter "machineName=myserver"
 
   # Run and watch progress
-  binmave scripts run 42 --watch`,
-	Args: cobra.ExactArgs(1),
-	RunE: runScriptsRun,
+  binmave scripts run 42 --watch
+
+  # Re-submit with the key of an earlier attempt
+  binmave scripts run 42 --idempotency-key 3f1c2a9e-0b7d-4c55-9a61-2d8e4f7b1a03
+
+Every submission carries an idempotency key (generated unless given with
+--idempotency-key), so a server that honours the Idempotency-Key header can
+recognise a duplicate. Submissions are only retried automatically when
+idempotency_keys is set in the configuration. If a submission fails
+ambiguously, for example with a timeout, matching recent executions are listed
+so you can check whether the script started before running it again.`,
+	Annotations: requires(auth.ExecuteScripts),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runScriptsRun,
 }
 
 func init() {
@@ -63,13 +78,14 @@ func init() {
 
 	scriptsRunCmd.Flags().StringVarP(&runAgentFilter, "filter", "f", "", "Filter agents (e.g., machineName=myserver)")
 	scriptsRunCmd.Flags().BoolVarP(&runWithWatch, "watch", "w", false, "Watch execution progress after starting")
+	scriptsRunCmd.Flags().StringVar(&runIdempotencyKey, "idempotency-key", "", "Idempotency key for the submission (default: generated)")
 
 	// Make 'scripts' without subcommand run 'scripts list'
 	scriptsCmd.RunE = runScriptsList
 }
 
 func runScriptsList(cmd *cobra.Command, args []string) error {
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -79,11 +95,11 @@ func runScriptsList(cmd *cobra.Command, args []string) error {
 
 	scripts, err := client.ListScripts(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list scripts: %w", err)
+		return apiError(err, "list scripts")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(scripts)
+	if isMachineOutput() {
+		return printOutput(scripts, nil)
 	}
 
 	if len(scripts) == 0 {
@@ -91,28 +107,43 @@ func runScriptsList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tTYPE\tVERSION\tTAGS\tTIMEOUT")
-	fmt.Fprintln(w, "--\t----\t----\t-------\t----\t-------")
+	table := output.NewTable(
+		output.Column{Header: "ID"},
+		output.Column{Header: "NAME"},
+		output.Column{Header: "TYPE"},
+		output.Column{Header: "VERSION"},
+		output.Column{Header: "TAGS"},
+		output.Column{Header: "TIMEOUT"},
+		output.Column{Header: "OUTPUT", Wide: true},
+		output.Column{Header: "REPOSITORY", Wide: true},
+		output.Column{Header: "DESCRIPTION", Wide: true},
+	)
 
 	for _, script := range scripts {
 		tags := strings.Join(script.Tags, ", ")
-		if len(tags) > 30 {
-			tags = tags[:27] + "..."
+		name := script.Name
+		if !isWideOutput() {
+			if len(tags) > 30 {
+				tags = tags[:27] + "..."
+			}
+			name = truncateString(name, 40)
 		}
-		name := truncateString(script.Name, 40)
 
-		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
-			script.ScriptID,
+		table.AddRow(
+			strconv.Itoa(script.ScriptID),
 			name,
 			script.ScriptType,
-			script.Version,
+			strconv.Itoa(script.Version),
 			tags,
 			script.ScriptTimeout,
+			script.OutputType,
+			script.RepoName,
+			truncateString(script.Description, 60),
 		)
 	}
-	w.Flush()
+	if err := printOutput(scripts, table); err != nil {
+		return err
+	}
 
 	fmt.Printf("\nTotal: %d scripts\n", len(scripts))
 
@@ -125,7 +156,7 @@ func runScriptsShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid script ID: %s", args[0])
 	}
 
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -135,11 +166,11 @@ func runScriptsShow(cmd *cobra.Command, args []string) error {
 
 	script, err := client.GetScript(ctx, scriptID)
 	if err != nil {
-		return fmt.Errorf("failed to get script: %w", err)
+		return apiError(err, "get script")
 	}
 
-	if IsJSONOutput() {
-		return printJSON(script)
+	if isMachineOutput() {
+		return printOutput(script, nil)
 	}
 
 	fmt.Printf("Script Details\n")
@@ -166,7 +197,7 @@ func runScriptsRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid script ID: %s", args[0])
 	}
 
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -177,35 +208,47 @@ func runScriptsRun(cmd *cobra.Command, args []string) error {
 	// Get script details first
 	script, err := client.GetScript(ctx, scriptID)
 	if err != nil {
-		return fmt.Errorf("failed to get script: %w", err)
+		return apiError(err, "get script")
 	}
 
 	// Build execute request
+	idempotencyKey := runIdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = api.NewIdempotencyKey()
+	}
+
 	req := api.ExecuteRequest{
 		FilterGridString: runAgentFilter,
 		CleanSandBox:     false,
+		IdempotencyKey:   idempotencyKey,
 	}
 
-	fmt.Printf("Executing script: %s (ID: %d)\n", script.Name, script.ScriptID)
-	if runAgentFilter != "" {
-		fmt.Printf("Filter: %s\n", runAgentFilter)
-	} else {
-		fmt.Println("Target: All agents")
+	// Keep machine-readable output parseable
+	if !isMachineOutput() {
+		fmt.Printf("Executing script: %s (ID: %d)\n", script.Name, script.ScriptID)
+		if runAgentFilter != "" {
+			fmt.Printf("Filter: %s\n", runAgentFilter)
+		} else {
+			fmt.Println("Target: All agents")
+		}
 	}
 
 	// Execute the script
+	submitted := time.Now()
 	result, err := client.ExecuteScript(ctx, scriptID, req)
 	if err != nil {
-		return fmt.Errorf("failed to execute script: %w", err)
+		return submissionError(client, script.ScriptID, req, submitted, err)
 	}
 
-	if IsJSONOutput() {
-		return printJSON(result)
+	if isMachineOutput() {
+		return printOutput(result, nil)
 	}
 
 	fmt.Printf("\n✓ Execution started\n")
 	fmt.Printf("  Execution ID: %s\n", result.ExecutionID)
-	fmt.Printf("  Expected agents: %d\n", result.ExpectedAgents)
+	if result.ExpectedAgents > 0 {
+		fmt.Printf("  Expected agents: %d\n", result.ExpectedAgents)
+	}
 
 	if runWithWatch {
 		fmt.Println()
@@ -219,6 +262,39 @@ func runScriptsRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// submissionError explains a failed submission. When the server may have
+// accepted it anyway, recent executions that match it are listed; they are
+// not assumed to be this submission, as anyone may have started them.
+func submissionError(client api.Backend, scriptID int, req api.ExecuteRequest, submitted time.Time, submitErr error) error {
+	err := apiError(submitErr, "execute scripts")
+	if !api.IsAmbiguous(submitErr) {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Allow for clock skew between this machine and the server
+	matches, findErr := api.FindRecentExecutions(ctx, client, scriptID, req, submitted.Add(-2*time.Minute))
+	if findErr != nil {
+		return fmt.Errorf("%w\nThe script may have started anyway; recent executions could not be checked (%v). Check 'binmave executions' before running it again",
+			err, findErr)
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("%w\nNo matching execution was found, but the script may still have started. Check 'binmave executions' before running it again", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("The script may have started anyway. Recent executions with the same script, filter and inputs:")
+	for _, execution := range matches {
+		fmt.Fprintf(&b, "\n  %s  %s", execution.ExecutionID, execution.Created.Local().Format("2006-01-02 15:04:05"))
+		if execution.CreatedBy != "" {
+			fmt.Fprintf(&b, "  by %s", execution.CreatedBy)
+		}
+	}
+	return fmt.Errorf("%w\n%s", err, b.String())
+}
+
 // runWatchInternal is called when --watch flag is used with scripts run
 func runWatchInternal(executionID string) error {
 	// Call the watch command programmatically
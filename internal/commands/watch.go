@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/auth"
 )
 
 var (
@@ -23,8 +24,9 @@ var watchCmd = &cobra.Command{
 as agents complete their work.
 
 Press Ctrl+C to stop watching (the execution continues in the background).`,
-	Args: cobra.ExactArgs(1),
-	RunE: runWatch,
+	Annotations: requires(auth.ViewExecutions),
+	Args:        cobra.ExactArgs(1),
+	RunE:        runWatch,
 }
 
 func init() {
@@ -34,7 +36,7 @@ func init() {
 func runWatch(cmd *cobra.Command, args []string) error {
 	executionID := args[0]
 
-	client, err := api.NewClient()
+	client, err := newBackend()
 	if err != nil {
 		return err
 	}
@@ -44,7 +46,7 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	execution, err := client.GetExecution(ctx, executionID)
 	cancel()
 	if err != nil {
-		return fmt.Errorf("failed to get execution: %w", err)
+		return apiError(err, "get execution")
 	}
 
 	// Handle Ctrl+C gracefully
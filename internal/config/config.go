@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/spf13/viper"
 )
@@ -10,6 +11,15 @@ import (
 const (
 	DefaultServer  = "https://dib3oav9kh29t.cloudfront.net"
 	DefaultTimeout = "5m"
+
+	DefaultRetryMaxAttempts = 4
+	DefaultRetryMaxWait     = "30s"
+
+	DefaultPageSize         = 100
+	DefaultFetchConcurrency = 4
+
+	DefaultCacheMaxSize = "1GB"
+
 	ConfigDir      = ".binmave"
 	ConfigFile     = "config"
 	CredentialsFile = "credentials"
@@ -18,8 +28,39 @@ const (
 type Config struct {
 	Server  string `mapstructure:"server"`
 	Timeout string `mapstructure:"timeout"`
+
+	// Retry budget for transient API failures
+	RetryMaxAttempts int    `mapstructure:"retry_max_attempts"`
+	RetryMaxWait     string `mapstructure:"retry_max_wait"`
+
+	// Set when the server is known to honour the Idempotency-Key header
+	IdempotencyKeys bool `mapstructure:"idempotency_keys"`
+
+	// Result page fetching
+	PageSize         int `mapstructure:"page_size"`
+	FetchConcurrency int `mapstructure:"fetch_concurrency"`
+
+	// Local cache of completed executions
+	CacheMaxSize string `mapstructure:"cache_max_size"`
+
+	// TLS and proxy settings shared by the API and auth clients
+	CAFile             string `mapstructure:"ca_file"`
+	ClientCert         string `mapstructure:"client_cert"`
+	ClientKey          string `mapstructure:"client_key"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	Proxy              string `mapstructure:"proxy"`
+
+	// Roles (compared case-insensitively) that may view data but not execute scripts
+	ReadOnlyRoles []string `mapstructure:"read_only_roles"`
+
+	// Results table columns chosen for each script, by script ID
+	ScriptColumns map[string][]string `mapstructure:"script_columns"`
 }
 
+// DefaultReadOnlyRoles are the role names treated as read-only unless
+// read_only_roles is configured
+var DefaultReadOnlyRoles = []string{"readonly", "read-only", "read_only", "reader", "viewer", "auditor", "guest"}
+
 var cfg *Config
 
 // Init initializes the configuration
@@ -41,6 +82,12 @@ func Init() error {
 	// Set defaults
 	viper.SetDefault("server", DefaultServer)
 	viper.SetDefault("timeout", DefaultTimeout)
+	viper.SetDefault("retry_max_attempts", DefaultRetryMaxAttempts)
+	viper.SetDefault("retry_max_wait", DefaultRetryMaxWait)
+	viper.SetDefault("page_size", DefaultPageSize)
+	viper.SetDefault("fetch_concurrency", DefaultFetchConcurrency)
+	viper.SetDefault("cache_max_size", DefaultCacheMaxSize)
+	viper.SetDefault("read_only_roles", DefaultReadOnlyRoles)
 
 	// Read config file if it exists
 	if err := viper.ReadInConfig(); err != nil {
@@ -61,8 +108,14 @@ func Init() error {
 func Get() *Config {
 	if cfg == nil {
 		cfg = &Config{
-			Server:  DefaultServer,
-			Timeout: DefaultTimeout,
+			Server:           DefaultServer,
+			Timeout:          DefaultTimeout,
+			RetryMaxAttempts: DefaultRetryMaxAttempts,
+			RetryMaxWait:     DefaultRetryMaxWait,
+			PageSize:         DefaultPageSize,
+			FetchConcurrency: DefaultFetchConcurrency,
+			CacheMaxSize:     DefaultCacheMaxSize,
+			ReadOnlyRoles:    DefaultReadOnlyRoles,
 		}
 	}
 	return cfg
@@ -79,6 +132,30 @@ func SetServer(server string) error {
 	return saveConfig()
 }
 
+// GetScriptColumns returns the results table columns remembered for a
+// script, or nil when all columns are shown
+func GetScriptColumns(scriptID int) []string {
+	return Get().ScriptColumns[strconv.Itoa(scriptID)]
+}
+
+// SetScriptColumns remembers the results table columns for a script; empty
+// columns forget the choice
+func SetScriptColumns(scriptID int, columns []string) error {
+	c := Get()
+	if c.ScriptColumns == nil {
+		c.ScriptColumns = make(map[string][]string)
+	}
+	key := strconv.Itoa(scriptID)
+	if len(columns) == 0 {
+		delete(c.ScriptColumns, key)
+	} else {
+		c.ScriptColumns[key] = columns
+	}
+
+	viper.Set("script_columns", c.ScriptColumns)
+	return saveConfig()
+}
+
 // getConfigDir returns the path to the config directory
 func getConfigDir() (string, error) {
 	home, err := os.UserHomeDir()
@@ -0,0 +1,159 @@
+// Package httpclient builds the HTTP transport shared by the API and auth clients,
+// applying the proxy, CA and client certificate settings from the configuration.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/config"
+)
+
+// Settings are the transport options read from the configuration
+type Settings struct {
+	CAFile             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	Proxy              string
+}
+
+var (
+	mu        sync.Mutex
+	cached    http.RoundTripper
+	cachedFor Settings
+	warnOnce  sync.Once
+)
+
+// FromConfig returns the transport settings of the current configuration
+func FromConfig() Settings {
+	cfg := config.Get()
+	return Settings{
+		CAFile:             cfg.CAFile,
+		ClientCert:         cfg.ClientCert,
+		ClientKey:          cfg.ClientKey,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		Proxy:              cfg.Proxy,
+	}
+}
+
+// Transport returns the shared transport for the current configuration.
+// It is built once and reused so connections are pooled across clients.
+func Transport() (http.RoundTripper, error) {
+	settings := FromConfig()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cached != nil && cachedFor == settings {
+		return cached, nil
+	}
+
+	transport, err := NewTransport(settings)
+	if err != nil {
+		return nil, err
+	}
+	cached, cachedFor = transport, settings
+	return transport, nil
+}
+
+// New returns an http.Client using the shared transport
+func New(timeout time.Duration) (*http.Client, error) {
+	transport, err := Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// NewTransport builds a transport from explicit settings
+func NewTransport(s Settings) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(s.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if s.CAFile != "" {
+		pem, err := os.ReadFile(expandHome(s.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file %s contains no PEM certificates", s.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if s.ClientCert != "" || s.ClientKey != "" {
+		if s.ClientCert == "" || s.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(expandHome(s.ClientCert), expandHome(s.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.InsecureSkipVerify {
+		warnOnce.Do(func() {
+			fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is DISABLED (insecure_skip_verify).")
+			fmt.Fprintln(os.Stderr, "WARNING: Connections can be intercepted. Use ca_file with your inspection CA instead.")
+		})
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// proxyFunc returns the proxy selector for a configured proxy URL.
+// An empty value uses the HTTP(S)_PROXY environment, "none" disables proxying.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch strings.ToLower(proxy) {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "none", "direct":
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
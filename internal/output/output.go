@@ -0,0 +1,227 @@
+// Package output prints command results in the format selected with -o/--output
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatWide     Format = "wide"
+	FormatJSON     Format = "json"
+	FormatNDJSON   Format = "ndjson"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatTSV      Format = "tsv"
+	FormatTemplate Format = "go-template"
+)
+
+// Formats describes the accepted --output values
+const Formats = "table, wide, json, ndjson, yaml, csv, tsv or go-template=<template>"
+
+// Printer writes data in one output format
+type Printer struct {
+	format Format
+	tmpl   *template.Template
+	out    io.Writer
+}
+
+// New parses an --output value ("" means table)
+func New(spec string, out io.Writer) (*Printer, error) {
+	p := &Printer{format: FormatTable, out: out}
+
+	if text, ok := strings.CutPrefix(spec, string(FormatTemplate)+"="); ok {
+		tmpl, err := template.New("output").Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %w", err)
+		}
+		p.format = FormatTemplate
+		p.tmpl = tmpl
+		return p, nil
+	}
+
+	switch Format(strings.ToLower(spec)) {
+	case "", FormatTable:
+	case FormatWide, FormatJSON, FormatNDJSON, FormatYAML, FormatCSV, FormatTSV:
+		p.format = Format(strings.ToLower(spec))
+	case FormatTemplate:
+		return nil, fmt.Errorf("missing template: use -o go-template='{{...}}'")
+	default:
+		return nil, fmt.Errorf("unknown output format %q: use %s", spec, Formats)
+	}
+	return p, nil
+}
+
+// Format returns the selected format
+func (p *Printer) Format() Format {
+	return p.format
+}
+
+// IsHuman reports whether output is meant for people (table or wide)
+func (p *Printer) IsHuman() bool {
+	return p.format == FormatTable || p.format == FormatWide
+}
+
+// Records reports whether the format prints one record per line (ndjson, csv, tsv)
+func (p *Printer) Records() bool {
+	return p.format == FormatNDJSON || p.format == FormatCSV || p.format == FormatTSV
+}
+
+// Print writes data in the selected format. Table and wide output render
+// table; without one, data is printed as YAML. Slices are printed as one
+// record per element in ndjson, csv and tsv.
+func (p *Printer) Print(data interface{}, table *Table) error {
+	switch p.format {
+	case FormatTable, FormatWide:
+		if table == nil {
+			return p.printYAML(data)
+		}
+		return table.Write(p.out, p.format == FormatWide)
+	case FormatJSON:
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case FormatNDJSON:
+		encoder := json.NewEncoder(p.out)
+		for _, item := range elements(data) {
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		return p.printYAML(data)
+	case FormatCSV, FormatTSV:
+		return p.printDelimited(elements(data))
+	case FormatTemplate:
+		return p.printTemplate(data)
+	}
+	return nil
+}
+
+// elements returns the elements of a slice, or data itself
+func elements(data interface{}) []interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{data}
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items
+}
+
+// printYAML converts data through JSON so keys match the json output
+func (p *Printer) printYAML(data interface{}) error {
+	out, err := toYAML(data)
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(out)
+	return err
+}
+
+func toYAML(data interface{}) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML; parsing it into a node keeps the field order
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle drops the flow and quoting styles inherited from JSON
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// printTemplate executes the template against data as it appears in json output
+func (p *Printer) printTemplate(data interface{}) error {
+	value, err := generic(data)
+	if err != nil {
+		return err
+	}
+	return p.tmpl.Execute(p.out, value)
+}
+
+// generic round-trips data through JSON so templates use the json field names
+func generic(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// printDelimited writes items as csv or tsv with a header of their json fields
+func (p *Printer) printDelimited(items []interface{}) error {
+	var columns []string
+	seen := make(map[string]bool)
+	records := make([]record, 0, len(items))
+
+	for _, item := range items {
+		rec, err := flatten(item)
+		if err != nil {
+			return err
+		}
+		for _, key := range rec.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		records = append(records, rec)
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	w := newDelimitedWriter(p.out, p.format)
+	if err := w.write(columns); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if err := w.write(rec.row(columns)); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// record is one item flattened to its top-level json fields, in field order
+type record struct {
+	keys   []string
+	values map[string]string
+}
+
+// row returns the record's values in column order
+func (r record) row(columns []string) []string {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = r.values[column]
+	}
+	return row
+}
+
+// flatten converts an item to a record. Scalars become their plain text and
+// nested objects and arrays stay compact JSON; non-objects use a "value" column.
+func flatten(item interface{}) (record, error) {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return record{}, err
+	}
+
+	rec := record{values: make(map[string]string)}
+	if len(raw) == 0 || raw[0] != '{' {
+		rec.keys = []string{"value"}
+		rec.values["value"] = cellText(raw)
+		return rec, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil {
+		return record{}, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return record{}, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return record{}, fmt.Errorf("unexpected JSON key %v", token)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return record{}, err
+		}
+		if _, dup := rec.values[key]; !dup {
+			rec.keys = append(rec.keys, key)
+		}
+		rec.values[key] = cellText(value)
+	}
+	return rec, nil
+}
+
+// cellText renders a JSON value as a csv cell
+func cellText(raw json.RawMessage) string {
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return ""
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+	}
+	return string(raw)
+}
+
+// delimitedWriter writes csv or tsv rows
+type delimitedWriter struct {
+	csv *csv.Writer
+	out io.Writer
+	err error
+}
+
+func newDelimitedWriter(out io.Writer, format Format) *delimitedWriter {
+	if format == FormatTSV {
+		return &delimitedWriter{out: out}
+	}
+	return &delimitedWriter{csv: csv.NewWriter(out)}
+}
+
+// tsvEscaper keeps every value on one line and in one column
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func (w *delimitedWriter) write(row []string) error {
+	if w.csv != nil {
+		return w.csv.Write(row)
+	}
+	if w.err != nil {
+		return w.err
+	}
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = tsvEscaper.Replace(value)
+	}
+	_, w.err = io.WriteString(w.out, strings.Join(escaped, "\t")+"\n")
+	return w.err
+}
+
+func (w *delimitedWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return w.err
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// streamFlushEvery is how many table rows are aligned and flushed together
+const streamFlushEvery = 100
+
+// Stream prints the items of a list one at a time, so the list never has to
+// be held in memory. JSON output is a single array, YAML a single sequence;
+// templates are executed once per item, each followed by a newline.
+type Stream struct {
+	p       *Printer
+	table   *Table
+	tw      *tabwriter.Writer
+	delim   *delimitedWriter
+	columns []string
+	count   int
+}
+
+// Stream starts a streamed list; columns are used for table and wide output
+func (p *Printer) Stream(columns ...Column) *Stream {
+	return &Stream{p: p, table: NewTable(columns...)}
+}
+
+// Count returns the number of items written
+func (s *Stream) Count() int {
+	return s.count
+}
+
+// Write prints one item; cells are its table row
+func (s *Stream) Write(item interface{}, cells ...string) error {
+	defer func() { s.count++ }()
+
+	switch s.p.format {
+	case FormatTable, FormatWide:
+		wide := s.p.format == FormatWide
+		if s.tw == nil {
+			s.tw = tabwriter.NewWriter(s.p.out, 0, 0, 2, ' ', 0)
+			s.table.writeHeader(s.tw, wide)
+		}
+		s.table.writeRow(s.tw, cells, wide)
+		// Column widths are aligned within each block
+		if (s.count+1)%streamFlushEvery == 0 {
+			return s.tw.Flush()
+		}
+		return nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := "["
+		if s.count > 0 {
+			sep = ","
+		}
+		_, err = fmt.Fprintf(s.p.out, "%s\n  %s", sep, data)
+		return err
+	case FormatNDJSON:
+		return json.NewEncoder(s.p.out).Encode(item)
+	case FormatYAML:
+		data, err := toYAML([]interface{}{item})
+		if err != nil {
+			return err
+		}
+		_, err = s.p.out.Write(data)
+		return err
+	case FormatCSV, FormatTSV:
+		rec, err := flatten(item)
+		if err != nil {
+			return err
+		}
+		// The first item decides the columns
+		if s.delim == nil {
+			s.delim = newDelimitedWriter(s.p.out, s.p.format)
+			s.columns = rec.keys
+			if err := s.delim.write(s.columns); err != nil {
+				return err
+			}
+		}
+		return s.delim.write(rec.row(s.columns))
+	case FormatTemplate:
+		if err := s.p.printTemplate(item); err != nil {
+			return err
+		}
+		_, err := io.WriteString(s.p.out, "\n")
+		return err
+	}
+	return nil
+}
+
+// Close finishes the output
+func (s *Stream) Close() error {
+	switch s.p.format {
+	case FormatTable, FormatWide:
+		if s.tw != nil {
+			return s.tw.Flush()
+		}
+	case FormatJSON:
+		if s.count == 0 {
+			_, err := io.WriteString(s.p.out, "[]\n")
+			return err
+		}
+		_, err := io.WriteString(s.p.out, "\n]\n")
+		return err
+	case FormatYAML:
+		if s.count == 0 {
+			_, err := io.WriteString(s.p.out, "[]\n")
+			return err
+		}
+	case FormatCSV, FormatTSV:
+		if s.delim != nil {
+			return s.delim.flush()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Column is a table column
+type Column struct {
+	Header string
+	Wide   bool // Only shown with -o wide
+}
+
+// Table is the human-readable form of a list
+type Table struct {
+	columns []Column
+	rows    [][]string
+}
+
+// NewTable creates a table with the given columns
+func NewTable(columns ...Column) *Table {
+	return &Table{columns: columns}
+}
+
+// AddRow appends a row with one cell per column
+func (t *Table) AddRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Write prints the table with a header and underline; wide includes wide columns
+func (t *Table) Write(out io.Writer, wide bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	t.writeHeader(w, wide)
+	for _, row := range t.rows {
+		t.writeRow(w, row, wide)
+	}
+	return w.Flush()
+}
+
+func (t *Table) writeHeader(w io.Writer, wide bool) {
+	var headers, underlines []string
+	for _, column := range t.columns {
+		if column.Wide && !wide {
+			continue
+		}
+		headers = append(headers, column.Header)
+		underlines = append(underlines, strings.Repeat("-", len(column.Header)))
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	fmt.Fprintln(w, strings.Join(underlines, "\t"))
+}
+
+func (t *Table) writeRow(w io.Writer, row []string, wide bool) {
+	var cells []string
+	for i, column := range t.columns {
+		if column.Wide && !wide {
+			continue
+		}
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		cells = append(cells, cell)
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
@@ -0,0 +1,106 @@
+package resultset
+
+import (
+	"sort"
+	"strings"
+)
+
+// Aggregate is a node path found in the answers of one or more agents
+type Aggregate struct {
+	Path       Path
+	Label      string
+	Leaf       bool
+	Value      Value    // Leaf value
+	AgentIDs   []string // Agents that have the path, each counted once
+	AgentNames []string
+	Children   []*Aggregate
+
+	seen map[string]bool
+}
+
+// Count returns the number of agents that have the path
+func (a *Aggregate) Count() int {
+	return len(a.AgentIDs)
+}
+
+// Aggregator merges the trees of many agents by path
+type Aggregator struct {
+	roots []*Aggregate
+	index map[string]*Aggregate
+}
+
+// NewAggregator creates an empty aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{index: make(map[string]*Aggregate)}
+}
+
+// Add merges one agent's tree
+func (a *Aggregator) Add(agentID, agentName string, nodes []*Node) {
+	a.add(agentID, agentName, nodes, nil, &a.roots)
+}
+
+func (a *Aggregator) add(agentID, agentName string, nodes []*Node, prefix Path, siblings *[]*Aggregate) {
+	for _, node := range nodes {
+		path := prefix.Child(node.Key)
+		key := path.String()
+
+		agg := a.index[key]
+		if agg == nil {
+			agg = &Aggregate{Path: path, Label: node.Label, Leaf: node.Leaf, seen: make(map[string]bool)}
+			if node.Leaf {
+				agg.Value = NewValue(node.Value)
+			}
+			a.index[key] = agg
+			*siblings = append(*siblings, agg)
+		}
+		if !agg.seen[agentID] {
+			agg.seen[agentID] = true
+			agg.AgentIDs = append(agg.AgentIDs, agentID)
+			agg.AgentNames = append(agg.AgentNames, agentName)
+		}
+
+		a.add(agentID, agentName, node.Children, path, &agg.Children)
+	}
+}
+
+// Len returns the number of distinct paths
+func (a *Aggregator) Len() int {
+	return len(a.index)
+}
+
+// Roots returns the top-level aggregates; siblings are ordered by agent count
+// (most common first), then by label, with values of the same field compared
+// by type so that sizes sort numerically
+func (a *Aggregator) Roots() []*Aggregate {
+	return a.Sorted(false)
+}
+
+// Sorted returns the top-level aggregates ordered like Roots, or least
+// common first when rarestFirst is set
+func (a *Aggregator) Sorted(rarestFirst bool) []*Aggregate {
+	sortAggregates(a.roots, rarestFirst)
+	return a.roots
+}
+
+func sortAggregates(aggs []*Aggregate, rarestFirst bool) {
+	sort.SliceStable(aggs, func(i, j int) bool {
+		if aggs[i].Count() != aggs[j].Count() {
+			return (aggs[i].Count() > aggs[j].Count()) != rarestFirst
+		}
+		if aggs[i].Leaf && aggs[j].Leaf && leafField(aggs[i].Label) == leafField(aggs[j].Label) {
+			if c := Compare(aggs[i].Value, aggs[j].Value); c != 0 {
+				return c < 0
+			}
+		}
+		return aggs[i].Label < aggs[j].Label
+	})
+	for _, agg := range aggs {
+		sortAggregates(agg.Children, rarestFirst)
+	}
+}
+
+// leafField returns the field name of a leaf label such as "Size: 1024"
+func leafField(label string) string {
+	field, _, _ := strings.Cut(label, ": ")
+	return field
+}
@@ -0,0 +1,59 @@
+package resultset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregatorCounts(t *testing.T) {
+	answers := []struct {
+		agentID, agentName, answer string
+	}{
+		{"1", "web-01", `[{"Name": "chrome"}, {"Name": "firefox"}]`},
+		{"2", "web-02", `[{"Name": "chrome"}]`},
+		{"3", "db-01", `[{"Name": "chrome"}, {"Name": "putty"}]`},
+		// The same item twice on one agent counts that agent once
+		{"3", "db-01", `[{"Name": "chrome"}]`},
+	}
+
+	a := NewAggregator()
+	for _, ans := range answers {
+		a.Add(ans.agentID, ans.agentName, Tree(mustParse(t, ans.answer)))
+	}
+
+	if a.Len() != 6 {
+		t.Errorf("Len() = %d, want 6 distinct paths", a.Len())
+	}
+
+	roots := a.Roots()
+	var labels []string
+	counts := make(map[string]int)
+	for _, root := range roots {
+		labels = append(labels, root.Label)
+		counts[root.Label] = root.Count()
+	}
+
+	// Most common first, then by label
+	if want := []string{"chrome", "firefox", "putty"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("roots = %q, want %q", labels, want)
+	}
+	if want := map[string]int{"chrome": 3, "firefox": 1, "putty": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	chrome := roots[0]
+	if !reflect.DeepEqual(chrome.AgentIDs, []string{"1", "2", "3"}) {
+		t.Errorf("chrome agents = %v", chrome.AgentIDs)
+	}
+	if !reflect.DeepEqual(chrome.AgentNames, []string{"web-01", "web-02", "db-01"}) {
+		t.Errorf("chrome agent names = %v", chrome.AgentNames)
+	}
+	if len(chrome.Children) != 1 || chrome.Children[0].Count() != 3 || !chrome.Children[0].Leaf {
+		t.Errorf("chrome children = %+v", chrome.Children)
+	}
+
+	rarest := a.Sorted(true)
+	if rarest[len(rarest)-1].Label != "chrome" {
+		t.Errorf("rarest first ends with %q, want chrome", rarest[len(rarest)-1].Label)
+	}
+}
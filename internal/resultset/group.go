@@ -0,0 +1,124 @@
+package resultset
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Group is the rows that share the values of the group-by columns
+type Group struct {
+	ID    string  // GroupKey of the group's rows
+	Key   []Value // Values of the group-by columns; missing values are zero
+	Rows  int
+	Stats map[string]*ColumnStats // Numeric columns, by name
+
+	agents map[string]bool
+}
+
+// Agents returns the number of distinct agents with rows in the group
+func (g *Group) Agents() int {
+	return len(g.agents)
+}
+
+// ColumnStats summarises the numbers of one column in a group
+type ColumnStats struct {
+	Count int // Rows with a number in the column
+	Min   float64
+	Max   float64
+	Sum   float64
+}
+
+func (s *ColumnStats) add(f float64) {
+	if s.Count == 0 || f < s.Min {
+		s.Min = f
+	}
+	if s.Count == 0 || f > s.Max {
+		s.Max = f
+	}
+	s.Sum += f
+	s.Count++
+}
+
+// Grouper groups rows by the values of one or more columns
+type Grouper struct {
+	columns []string
+	groups  []*Group
+	index   map[string]*Group
+}
+
+// NewGrouper creates an empty grouper for the group-by columns
+func NewGrouper(columns []string) *Grouper {
+	return &Grouper{columns: columns, index: make(map[string]*Group)}
+}
+
+// Add adds one row to its group
+func (g *Grouper) Add(agentID string, data map[string]Value) {
+	key := GroupKey(g.columns, data)
+	group := g.index[key]
+	if group == nil {
+		group = &Group{
+			ID:     key,
+			Key:    make([]Value, len(g.columns)),
+			Stats:  make(map[string]*ColumnStats),
+			agents: make(map[string]bool),
+		}
+		for i, col := range g.columns {
+			group.Key[i] = data[col]
+		}
+		g.index[key] = group
+		g.groups = append(g.groups, group)
+	}
+
+	group.Rows++
+	group.agents[agentID] = true
+	for col, value := range data {
+		if value.Kind != KindNumber {
+			continue
+		}
+		stats := group.Stats[col]
+		if stats == nil {
+			stats = &ColumnStats{}
+			group.Stats[col] = stats
+		}
+		stats.add(value.Float())
+	}
+}
+
+// Groups returns the groups, largest first; groups of the same size are
+// ordered by their values
+func (g *Grouper) Groups() []*Group {
+	sort.SliceStable(g.groups, func(i, j int) bool {
+		a, b := g.groups[i], g.groups[j]
+		if a.Rows != b.Rows {
+			return a.Rows > b.Rows
+		}
+		for k := range a.Key {
+			if c := Compare(a.Key[k], b.Key[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return g.groups
+}
+
+// GroupKey identifies the group of a row. Values of different types never
+// share a group, and a missing value differs from null and from "".
+func GroupKey(columns []string, data map[string]Value) string {
+	var b strings.Builder
+	for _, col := range columns {
+		value, ok := data[col]
+		switch {
+		case !ok:
+			b.WriteString("-")
+		case value.IsNull():
+			b.WriteString("0")
+		default:
+			b.WriteString(strconv.Itoa(int(value.Kind)))
+			b.WriteString(value.String())
+		}
+		b.WriteByte(0)
+	}
+	return b.String()
+}
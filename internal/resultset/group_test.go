@@ -0,0 +1,88 @@
+package resultset
+
+import (
+	"testing"
+)
+
+func TestGroupKeySeparatesMissingNullAndEmpty(t *testing.T) {
+	rows := map[string]map[string]Value{
+		"missing":      {},
+		"null":         {"User": NewValue(nil)},
+		"empty":        {"User": StringValue("")},
+		"string zero":  {"User": StringValue("0")},
+		"number zero":  {"User": NewValue(0.0)},
+		"string one":   {"User": StringValue("1")},
+		"number one":   {"User": NewValue(1.0)},
+		"string false": {"User": StringValue("false")},
+		"bool false":   {"User": NewValue(false)},
+	}
+
+	seen := make(map[string]string)
+	for name, data := range rows {
+		key := GroupKey([]string{"User"}, data)
+		if other, ok := seen[key]; ok {
+			t.Errorf("%s and %s share the group key %q", name, other, key)
+		}
+		seen[key] = name
+	}
+
+	// The same values always share a key
+	a := GroupKey([]string{"Name", "User"}, map[string]Value{"Name": StringValue("chrome"), "User": NewValue(nil)})
+	b := GroupKey([]string{"Name", "User"}, map[string]Value{"Name": StringValue("chrome"), "User": NewValue(nil), "Other": NewValue(3.0)})
+	if a != b {
+		t.Errorf("keys of equal group-by values differ: %q, %q", a, b)
+	}
+
+	// Column boundaries are kept: ("ab", "") is not ("a", "b")
+	c := GroupKey([]string{"X", "Y"}, map[string]Value{"X": StringValue("ab"), "Y": StringValue("")})
+	d := GroupKey([]string{"X", "Y"}, map[string]Value{"X": StringValue("a"), "Y": StringValue("b")})
+	if c == d {
+		t.Errorf("(ab, \"\") and (a, b) share the group key %q", c)
+	}
+}
+
+func TestGrouperStats(t *testing.T) {
+	rows := []struct {
+		agentID string
+		data    map[string]Value
+	}{
+		{"1", map[string]Value{"Name": StringValue("chrome"), "Mem": NewValue(100.0), "CPU": NewValue(1.5)}},
+		{"1", map[string]Value{"Name": StringValue("chrome"), "Mem": NewValue(-20.0)}},
+		{"2", map[string]Value{"Name": StringValue("chrome"), "Mem": NewValue(300.0), "CPU": StringValue("n/a")}},
+		{"2", map[string]Value{"Name": StringValue("putty"), "Mem": NewValue(7.0)}},
+		{"3", map[string]Value{"Mem": NewValue(1.0)}},
+	}
+
+	g := NewGrouper([]string{"Name"})
+	for _, row := range rows {
+		g.Add(row.agentID, row.data)
+	}
+	groups := g.Groups()
+
+	// Largest first, then by value; the missing name sorts first among singles
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+	chrome := groups[0]
+	if chrome.Key[0].String() != "chrome" || chrome.Rows != 3 || chrome.Agents() != 2 {
+		t.Errorf("first group = %v with %d rows on %d agents, want chrome with 3 rows on 2 agents", chrome.Key, chrome.Rows, chrome.Agents())
+	}
+
+	mem := chrome.Stats["Mem"]
+	if mem == nil || mem.Count != 3 || mem.Min != -20 || mem.Max != 300 || mem.Sum != 380 {
+		t.Errorf("chrome Mem stats = %+v, want count 3, min -20, max 300, sum 380", mem)
+	}
+	// Only numbers count towards the stats
+	cpu := chrome.Stats["CPU"]
+	if cpu == nil || cpu.Count != 1 || cpu.Min != 1.5 || cpu.Max != 1.5 || cpu.Sum != 1.5 {
+		t.Errorf("chrome CPU stats = %+v, want the single number 1.5", cpu)
+	}
+	if _, ok := chrome.Stats["Name"]; ok {
+		t.Error("stats computed for a string column")
+	}
+
+	putty := groups[2]
+	if putty.Key[0].String() != "putty" || putty.Stats["Mem"].Min != 7 || putty.Stats["Mem"].Max != 7 {
+		t.Errorf("last group = %v with Mem %+v, want putty with 7", putty.Key, putty.Stats["Mem"])
+	}
+}
@@ -0,0 +1,50 @@
+package resultset
+
+import "strings"
+
+// Path identifies a node within an answer by the keys of the nodes leading to it
+type Path []string
+
+// pathEscaper escapes the separator inside keys, which are often file paths
+var pathEscaper = strings.NewReplacer(`\`, `\\`, "/", `\/`)
+
+// String joins the keys with "/", escaping "/" and "\" inside keys so that
+// different paths never have the same string
+func (p Path) String() string {
+	var b strings.Builder
+	for _, key := range p {
+		b.WriteByte('/')
+		b.WriteString(pathEscaper.Replace(key))
+	}
+	return b.String()
+}
+
+// Child returns the path of a child node
+func (p Path) Child(key string) Path {
+	child := make(Path, len(p), len(p)+1)
+	copy(child, p)
+	return append(child, key)
+}
+
+// Last returns the final key, or "" for the root
+func (p Path) Last() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[len(p)-1]
+}
+
+// Paths returns the path of every node, parents before their children
+func Paths(nodes []*Node) []Path {
+	var paths []Path
+	walkPaths(nodes, nil, &paths)
+	return paths
+}
+
+func walkPaths(nodes []*Node, prefix Path, paths *[]Path) {
+	for _, node := range nodes {
+		path := prefix.Child(node.Key)
+		*paths = append(*paths, path)
+		walkPaths(node.Children, path, paths)
+	}
+}
@@ -0,0 +1,52 @@
+package resultset
+
+import "testing"
+
+func TestPathString(t *testing.T) {
+	tests := []struct {
+		name string
+		path Path
+		want string
+	}{
+		{"root", Path{}, ""},
+		{"plain keys", Path{"Software", "chrome"}, "/Software/chrome"},
+		{"slash in key", Path{"/usr/bin", "ls"}, `/\/usr\/bin/ls`},
+		{"backslash in key", Path{`C:\Windows`}, `/C:\\Windows`},
+		{"escaped separator", Path{`a\/b`}, `/a\\\/b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.path.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathStringIsUnique(t *testing.T) {
+	// Each pair would join to "/a/b" without escaping
+	pairs := [][2]Path{
+		{{"a", "b"}, {"a/b"}},
+		{{`a\`, "b"}, {`a\/b`}},
+		{{"a/", "b"}, {"a", "/b"}},
+	}
+	for _, pair := range pairs {
+		if pair[0].String() == pair[1].String() {
+			t.Errorf("%q and %q both format as %q", []string(pair[0]), []string(pair[1]), pair[0].String())
+		}
+	}
+}
+
+func TestPathChild(t *testing.T) {
+	parent := make(Path, 1, 4)
+	parent[0] = "a"
+
+	first := parent.Child("b")
+	second := parent.Child("c")
+	if first.String() != "/a/b" || second.String() != "/a/c" {
+		t.Errorf("children share storage: %v %v", first, second)
+	}
+	if first.Last() != "b" || (Path{}).Last() != "" {
+		t.Errorf("Last() = %q, %q", first.Last(), (Path{}).Last())
+	}
+}
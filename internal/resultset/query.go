@@ -0,0 +1,574 @@
+package resultset
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed search filter. Its syntax:
+//
+//	svchost              Any cell (or the agent name) contains the text
+//	"two words"          Quoted text; \" is a quote and other backslashes are kept
+//	/svc.*host/          Any cell matches the regular expression
+//	Name:svchost         The field contains the text (or matches /re/)
+//	Size>1000000         Compare the field: = != > >= < <=, by its type
+//	Path~"\\temp\\"      The field matches the regular expression
+//	Owner=null           The field is a JSON null
+//	not a, a and b, a or b, (...)
+//
+// Terms next to each other must all match. Text and regular expression
+// matching ignores case. "agent" is the agent name unless the answer has its
+// own agent field.
+type Query struct {
+	root queryNode
+}
+
+// QueryError is a syntax error at a byte offset of the query
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s (at %d)", e.Msg, e.Pos+1)
+}
+
+// QueryPrefix starts a search written in the query language
+const QueryPrefix = "?"
+
+// ParseSearch parses the text of a search box. Plain text is found anywhere
+// in a row exactly as typed, ignoring case, so paths, addresses and key=value
+// pairs need no quoting. Text starting with QueryPrefix is a query; error
+// positions count from the start of the whole text.
+func ParseSearch(s string) (*Query, error) {
+	if s == "" {
+		return nil, nil
+	}
+	trimmed := strings.TrimLeft(s, " \t")
+	rest, ok := strings.CutPrefix(trimmed, QueryPrefix)
+	if !ok {
+		return &Query{root: &textNode{text: strings.ToLower(s)}}, nil
+	}
+
+	q, err := ParseQuery(rest)
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		queryErr.Pos += len(s) - len(rest)
+	}
+	return q, err
+}
+
+// ParseQuery parses a search filter. An empty query returns nil, which
+// matches everything.
+func ParseQuery(s string) (*Query, error) {
+	p := &queryParser{s: s}
+	p.skipSpace()
+	if p.eof() {
+		return nil, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether a row matches
+func (q *Query) Match(agentName string, data map[string]Value) bool {
+	if q == nil {
+		return true
+	}
+	texts := make([]string, 0, len(data)+1)
+	texts = append(texts, agentName)
+	for _, value := range data {
+		texts = append(texts, value.String())
+	}
+	return q.root.match(&queryRecord{agent: agentName, data: data, texts: texts})
+}
+
+// MatchLabel reports whether a tree node label matches. A leaf labelled
+// "Size: 1024" has a Size field holding the number 1024; free text is matched
+// against the whole label.
+func (q *Query) MatchLabel(label string) bool {
+	if q == nil {
+		return true
+	}
+	record := &queryRecord{texts: []string{label}}
+	if field, text, ok := strings.Cut(label, ": "); ok {
+		record.data = map[string]Value{field: ParseScalar(text)}
+	}
+	return q.root.match(record)
+}
+
+// ParseScalar converts the text of a leaf back to a typed value
+func ParseScalar(text string) Value {
+	switch text {
+	case "null":
+		return NewValue(nil)
+	case "true", "false":
+		return NewValue(text == "true")
+	}
+	if isNumeric(text) {
+		f, _ := strconv.ParseFloat(text, 64)
+		return Value{Kind: KindNumber, text: text, num: f, raw: f}
+	}
+	return NewValue(text)
+}
+
+// queryRecord is what a query is evaluated against
+type queryRecord struct {
+	agent string
+	data  map[string]Value
+	texts []string // Searched by free text
+}
+
+// field looks a field up by exact name, then ignoring case
+func (r *queryRecord) field(name string) (Value, bool) {
+	if v, ok := r.data[name]; ok {
+		return v, true
+	}
+	for key, v := range r.data {
+		if strings.EqualFold(key, name) {
+			return v, true
+		}
+	}
+	if strings.EqualFold(name, "agent") && r.agent != "" {
+		return StringValue(r.agent), true
+	}
+	return Value{}, false
+}
+
+type queryNode interface {
+	match(r *queryRecord) bool
+}
+
+type andNode struct{ left, right queryNode }
+
+func (n *andNode) match(r *queryRecord) bool { return n.left.match(r) && n.right.match(r) }
+
+type orNode struct{ left, right queryNode }
+
+func (n *orNode) match(r *queryRecord) bool { return n.left.match(r) || n.right.match(r) }
+
+type notNode struct{ node queryNode }
+
+func (n *notNode) match(r *queryRecord) bool { return !n.node.match(r) }
+
+// textNode matches free text or a regular expression against every text
+type textNode struct {
+	text string // Lower case
+	re   *regexp.Regexp
+}
+
+func (n *textNode) match(r *queryRecord) bool {
+	for _, text := range r.texts {
+		if n.matchText(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *textNode) matchText(text string) bool {
+	if n.re != nil {
+		return n.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), n.text)
+}
+
+// fieldNode compares one field with a literal
+type fieldNode struct {
+	field string
+	op    string
+	lit   queryLiteral
+}
+
+func (n *fieldNode) match(r *queryRecord) bool {
+	v, ok := r.field(n.field)
+	if !ok {
+		// Missing fields match nothing, so "not" finds them
+		return false
+	}
+
+	switch n.op {
+	case ":", "~":
+		return n.lit.text.matchText(v.String()) || (v.Kind == KindTime && n.lit.text.matchText(v.Time().Local().Format("2006-01-02 15:04:05")))
+	case "=":
+		return n.lit.equal(v)
+	case "!=":
+		return !n.lit.equal(v)
+	}
+
+	c, ok := n.lit.compare(v)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// queryLiteral is the value side of a field comparison, parsed up front as
+// every type it could be compared with
+type queryLiteral struct {
+	raw    string
+	text   textNode
+	num    float64
+	isNum  bool
+	time   time.Time
+	isTime bool
+}
+
+func newQueryLiteral(raw string) queryLiteral {
+	lit := queryLiteral{raw: raw, text: textNode{text: strings.ToLower(raw)}}
+	if isNumeric(raw) {
+		lit.num, _ = strconv.ParseFloat(raw, 64)
+		lit.isNum = true
+	}
+	if t, ok := parseQueryTime(raw); ok {
+		lit.time, lit.isTime = t, true
+	}
+	return lit
+}
+
+func (l queryLiteral) equal(v Value) bool {
+	if v.IsNull() || l.raw == "null" {
+		return v.IsNull() && l.raw == "null"
+	}
+	if v.Kind == KindBool {
+		b, err := strconv.ParseBool(l.raw)
+		return err == nil && strconv.FormatBool(b) == v.String()
+	}
+	c, ok := l.compare(v)
+	return ok && c == 0
+}
+
+// compare orders the value relative to the literal. Numbers, list lengths and
+// times compare by type when the literal has that type too; everything else
+// compares as text, ignoring case.
+func (l queryLiteral) compare(v Value) (int, bool) {
+	switch {
+	case v.Kind == KindNull:
+		return 0, false
+	case (v.Kind == KindNumber || v.Kind == KindList) && l.isNum:
+		return compareFloat(v.Float(), l.num), true
+	case v.Kind == KindTime && l.isTime:
+		return v.Time().Compare(l.time), true
+	}
+	return strings.Compare(strings.ToLower(v.String()), strings.ToLower(l.raw)), true
+}
+
+// isNumeric reports whether text is a decimal number; unlike ParseFloat it
+// rejects "NaN", "Inf" and hexadecimal
+func isNumeric(text string) bool {
+	if text == "" || !strings.ContainsRune("0123456789+-.", rune(text[0])) {
+		return false
+	}
+	if strings.ContainsAny(text, "xXpP_") {
+		return false
+	}
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// queryTimeLayouts are accepted for times in queries; without a zone they
+// are local time
+var queryTimeLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+}
+
+func parseQueryTime(s string) (time.Time, bool) {
+	if t, ok := parseTime(s); ok {
+		return t, true
+	}
+	for _, layout := range queryTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// queryParser is a recursive descent parser over the query text
+type queryParser struct {
+	s   string
+	pos int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return &QueryError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *queryParser) skipSpace() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// keyword consumes a word such as "and" when it stands alone
+func (p *queryParser) keyword(word string) bool {
+	end := p.pos + len(word)
+	if end > len(p.s) || !strings.EqualFold(p.s[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.s) && !strings.ContainsRune(" \t()", rune(p.s[end])) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.keyword("or") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.s[p.pos] == ')' {
+			return left, nil
+		}
+		start := p.pos
+		if p.keyword("or") {
+			p.pos = start
+			return left, nil
+		}
+		p.keyword("and")
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	p.skipSpace()
+	if p.keyword("not") {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("expected a search term")
+	}
+
+	switch p.s[p.pos] {
+	case '(':
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.s[p.pos] != ')' {
+			return nil, p.errorf("missing ')'")
+		}
+		p.pos++
+		return node, nil
+	case ')':
+		return nil, p.errorf("unexpected ')'")
+	case '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &textNode{text: strings.ToLower(text)}, nil
+	case '/':
+		re, err := p.parseRegex()
+		if err != nil {
+			return nil, err
+		}
+		return &textNode{re: re}, nil
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t()\":=!<>~", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	word := p.s[start:p.pos]
+
+	op := p.parseOp()
+	if op == "" {
+		if p.eof() || strings.ContainsRune(" \t()", rune(p.s[p.pos])) {
+			return &textNode{text: strings.ToLower(word)}, nil
+		}
+		if p.s[p.pos] == '!' {
+			return nil, p.errorf("expected '=' after '!'")
+		}
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	if word == "" {
+		return nil, &QueryError{Pos: start, Msg: fmt.Sprintf("expected a field name before '%s'", op)}
+	}
+	return p.parseComparison(word, op)
+}
+
+// parseOp consumes a comparison operator, if there is one
+func (p *queryParser) parseOp() string {
+	for _, op := range []string{"!=", ">=", "<=", ":", "=", ">", "<", "~"} {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// parseComparison parses the value after field and operator. Unquoted values
+// run to the next space or ')', so Path:C:\Windows needs no quotes.
+func (p *queryParser) parseComparison(field, op string) (queryNode, error) {
+	if p.eof() || strings.ContainsRune(" \t)", rune(p.s[p.pos])) {
+		return nil, p.errorf("expected a value after '%s'", op)
+	}
+
+	var raw string
+	var re *regexp.Regexp
+	var err error
+	parsed := true
+	switch {
+	case p.s[p.pos] == '"':
+		raw, err = p.parseQuoted()
+	case p.s[p.pos] == '/' && (op == ":" || op == "~"):
+		re, err = p.parseRegex()
+	default:
+		parsed = false
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !parsed {
+		start := p.pos
+		for !p.eof() && !strings.ContainsRune(" \t)", rune(p.s[p.pos])) {
+			p.pos++
+		}
+		raw = p.s[start:p.pos]
+	}
+
+	lit := newQueryLiteral(raw)
+	switch {
+	case re != nil:
+		lit.text = textNode{re: re}
+	case op == "~":
+		if lit.text.re, err = regexp.Compile("(?i)" + raw); err != nil {
+			return nil, p.errorf("invalid regular expression: %v", err)
+		}
+	}
+	return &fieldNode{field: field, op: op, lit: lit}, nil
+}
+
+// parseQuoted reads a quoted string. \" is a quote; other backslashes are
+// kept as typed, so Windows paths need no escaping and "\\temp\\" is still a
+// regular expression for \temp\.
+func (p *queryParser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // Opening quote
+
+	var b strings.Builder
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '"':
+			b.WriteByte('"')
+			p.pos += 2
+		case c == '\\' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '\\':
+			b.WriteString(`\\`)
+			p.pos += 2
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", &QueryError{Pos: start, Msg: "unterminated quote"}
+}
+
+// parseRegex reads a /regular expression/, with \/ for a slash, and compiles
+// it to ignore case
+func (p *queryParser) parseRegex() (*regexp.Regexp, error) {
+	start := p.pos
+	p.pos++ // Opening slash
+
+	var b strings.Builder
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.s) && p.s[p.pos+1] == '/':
+			b.WriteByte('/')
+			p.pos += 2
+		case c == '/':
+			p.pos++
+			re, err := regexp.Compile("(?i)" + b.String())
+			if err != nil {
+				return nil, &QueryError{Pos: start, Msg: fmt.Sprintf("invalid regular expression: %v", err)}
+			}
+			return re, nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return nil, &QueryError{Pos: start, Msg: "unterminated regular expression"}
+}
@@ -0,0 +1,163 @@
+package resultset
+
+import (
+	"errors"
+	"testing"
+)
+
+// queryRow is the row the matching tests run against
+var queryRow = map[string]Value{
+	"Name":    StringValue("svchost.exe"),
+	"Path":    StringValue(`C:\Windows\System32\svchost.exe`),
+	"Remote":  StringValue("10.0.0.1:443"),
+	"Cmd":     StringValue("/usr/bin/env key=value"),
+	"Size":    NewValue(1048576.0),
+	"Owner":   NewValue(nil),
+	"Started": NewValue("2024-05-03T10:00:00Z"),
+	"Tags":    NewValue([]interface{}{"a", "b"}),
+}
+
+func TestParseSearchMatch(t *testing.T) {
+	tests := []struct {
+		search string
+		want   bool
+	}{
+		// Plain text is found as typed, without any syntax
+		{"", true},
+		{"svchost", true},
+		{"SVCHOST", true},
+		{`C:\Windows`, true},
+		{"10.0.0.1:443", true},
+		{"/usr/bin", true},
+		{"/tmp", false},
+		{"key=value", true},
+		{"Size>1", false},
+		{"not svchost", false},
+		{"web-01", true}, // Agent name
+		{"notepad", false},
+
+		// Queries start with the prefix
+		{"?svchost", true},
+		{"  ?svchost", true},
+		{"?Name:svchost", true},
+		{"?name:SVCHOST", true},
+		{"?Name:notepad", false},
+		{"?not Name:notepad", true},
+		{"?Size>1000000", true},
+		{"?Size>=1048576", true},
+		{"?Size<1000", false},
+		{"?Size=1048576", true},
+		{"?Size!=1048576", false},
+		{"?Owner=null", true},
+		{"?Name=null", false},
+		{"?Missing:x", false},
+		{"?not Missing:x", true},
+		{`?Path~"\\system32\\"`, true},
+		{"?Path:/svc.*\\.exe$/", true},
+		{"?/^10\\.0\\./", true},
+		{"?Path:C:\\Windows", true},
+		{"?Started>2024-05-01", true},
+		{"?Started<2024-05-01", false},
+		{"?Tags>1", true},
+		{"?agent=web-01", true},
+		{"?agent=web-02", false},
+		{"?Name:svchost Size>1", true},
+		{"?Name:svchost and Size<1", false},
+		{"?Name:notepad or Size>1", true},
+		{"?(Name:notepad or Name:svchost) and not Owner!=null", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			q, err := ParseSearch(tt.search)
+			if err != nil {
+				t.Fatalf("ParseSearch(%q): %v", tt.search, err)
+			}
+			if got := q.Match("web-01", queryRow); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryMatchLabel(t *testing.T) {
+	tests := []struct {
+		query string
+		label string
+		want  bool
+	}{
+		{"Size>1000", "Size: 1024", true},
+		{"Size>1000", "Size: 999", false},
+		{"Owner=null", "Owner: null", true},
+		{"chrome", "chrome", true},
+		{"Name:chrome", "chrome", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.label, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+			}
+			if got := q.MatchLabel(tt.label); got != tt.want {
+				t.Errorf("MatchLabel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"/tmp", 0, "unterminated regular expression"},
+		{`"abc`, 0, "unterminated quote"},
+		{"Name:", 5, "expected a value after ':'"},
+		{"Size> ", 5, "expected a value after '>'"},
+		{"(a", 2, "missing ')'"},
+		{"a)", 1, "unexpected ')'"},
+		{"=x", 0, "expected a field name before '='"},
+		{"a!b", 1, "expected '=' after '!'"},
+		{"not", 3, "expected a search term"},
+		{"a or", 4, "expected a search term"},
+		{"Name:/[/", 5, "invalid regular expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("got %v, want a QueryError", err)
+			}
+			if queryErr.Pos != tt.pos {
+				t.Errorf("Pos = %d, want %d (%s)", queryErr.Pos, tt.pos, queryErr.Msg)
+			}
+			if len(queryErr.Msg) < len(tt.msg) || queryErr.Msg[:len(tt.msg)] != tt.msg {
+				t.Errorf("Msg = %q, want prefix %q", queryErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseSearchErrorPositions(t *testing.T) {
+	tests := []struct {
+		search string
+		pos    int
+	}{
+		{"?/tmp", 1},
+		{"?(a", 3},
+		{"  ?Name:", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			_, err := ParseSearch(tt.search)
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("got %v, want a QueryError", err)
+			}
+			if queryErr.Pos != tt.pos {
+				t.Errorf("Pos = %d, want %d", queryErr.Pos, tt.pos)
+			}
+		})
+	}
+}
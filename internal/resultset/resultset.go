@@ -0,0 +1,163 @@
+// Package resultset interprets script answers as rows, columns, trees and
+// paths, the same way for the TUI, exports, comparisons and aggregation
+package resultset
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// Row is one item of an agent's result, flattened to dotted column names
+type Row struct {
+	AgentName string
+	AgentID   string
+	Index     int // Position of the item within the agent's result
+	Data      map[string]Value
+	HasError  bool
+	Raw       bool // The answer was not JSON and is kept as a single Value
+}
+
+// Rows expands a result into one row per item. A failed result becomes a
+// single row with an Error column, an answer that is not JSON a single row
+// with a Value column, and an empty answer a single row without data.
+func Rows(r api.ExecutionResult) []Row {
+	// For errors, use RawStdError instead of AnswerJSON
+	if r.HasError {
+		errorMsg := r.RawStdError
+		if errorMsg == "" {
+			errorMsg = r.AnswerJSON // Fallback to AnswerJSON if no stderr
+		}
+		if errorMsg == "" {
+			errorMsg = "(no error message)"
+		}
+		return []Row{{
+			AgentName: r.AgentName,
+			AgentID:   r.AgentID,
+			Data:      map[string]Value{"Error": StringValue(errorMsg)},
+			HasError:  true,
+		}}
+	}
+
+	data, err := Parse(r.AnswerJSON)
+	if err != nil {
+		return []Row{{
+			AgentName: r.AgentName,
+			AgentID:   r.AgentID,
+			Data:      map[string]Value{"Value": StringValue(r.AnswerJSON)},
+			Raw:       true,
+		}}
+	}
+
+	items := NormalizeToRows(data)
+	if len(items) == 0 {
+		return []Row{{
+			AgentName: r.AgentName,
+			AgentID:   r.AgentID,
+			Data:      map[string]Value{},
+		}}
+	}
+
+	rows := make([]Row, len(items))
+	for i, item := range items {
+		rows[i] = Row{
+			AgentName: r.AgentName,
+			AgentID:   r.AgentID,
+			Index:     i,
+			Data:      FlattenObject(item, ""),
+		}
+	}
+	return rows
+}
+
+// Parse decodes an answer. Numbers are kept as json.Number, so large IDs and
+// sizes keep their exact text instead of turning into floats.
+func Parse(answer string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(answer))
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return data, nil
+}
+
+// NormalizeToRows converts JSON data to array of maps
+func NormalizeToRows(data interface{}) []map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		var rows []map[string]interface{}
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, m)
+			}
+		}
+		return rows
+	case map[string]interface{}:
+		return []map[string]interface{}{v}
+	}
+	return nil
+}
+
+// FlattenObject flattens nested objects with dot notation. Values keep their
+// JSON type; arrays become lists shown inline or as an item count.
+func FlattenObject(obj map[string]interface{}, prefix string) map[string]Value {
+	result := make(map[string]Value)
+
+	for key, value := range obj {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+
+		if v, ok := value.(map[string]interface{}); ok {
+			// Flatten nested objects
+			nested := FlattenObject(v, fullKey)
+			for k, val := range nested {
+				result[k] = val
+			}
+			continue
+		}
+		result[fullKey] = NewValue(value)
+	}
+
+	return result
+}
+
+// SortColumns returns columns sorted with priority columns first
+func SortColumns(columnSet map[string]bool) []string {
+	// Priority columns that should appear first
+	priority := []string{"Name", "name", "User", "user", "Group", "group", "Path", "path",
+		"Value", "value", "Status", "status", "Type", "type", "ID", "id", "Id"}
+
+	var columns []string
+	added := make(map[string]bool)
+
+	// Add priority columns first
+	for _, p := range priority {
+		if columnSet[p] && !added[p] {
+			columns = append(columns, p)
+			added[p] = true
+		}
+	}
+
+	// Add remaining columns alphabetically
+	var remaining []string
+	for col := range columnSet {
+		if !added[col] {
+			remaining = append(remaining, col)
+		}
+	}
+	sort.Strings(remaining)
+	columns = append(columns, remaining...)
+
+	return columns
+}
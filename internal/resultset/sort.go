@@ -0,0 +1,75 @@
+package resultset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortKey orders rows by one column
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// String formats the key as accepted by ParseSort
+func (k SortKey) String() string {
+	if k.Desc {
+		return k.Column + ":desc"
+	}
+	return k.Column
+}
+
+// ParseSort parses a comma-separated list of columns, each optionally
+// followed by ":asc" or ":desc", such as "Size:desc,Name"
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key := SortKey{Column: part}
+		if column, order, ok := strings.Cut(part, ":"); ok {
+			key.Column = strings.TrimSpace(column)
+			switch strings.ToLower(strings.TrimSpace(order)) {
+			case "asc":
+			case "desc":
+				key.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid sort order %q for column %q: use asc or desc", order, key.Column)
+			}
+		}
+		if key.Column == "" {
+			return nil, fmt.Errorf("invalid sort key %q: missing column", part)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// CompareBy orders two rows by each key in turn. Missing values sort last
+// in both directions, so empty cells never crowd the top of the table.
+func CompareBy(keys []SortKey, a, b map[string]Value) int {
+	for _, key := range keys {
+		va, okA := a[key.Column]
+		vb, okB := b[key.Column]
+		switch {
+		case !okA && !okB:
+			continue
+		case !okA:
+			return 1
+		case !okB:
+			return -1
+		}
+
+		c := Compare(va, vb)
+		if key.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
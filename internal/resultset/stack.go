@@ -0,0 +1,123 @@
+package resultset
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Threshold is the agent count at or below which an item is an anomaly,
+// either absolute or as a percentage of the agents
+type Threshold struct {
+	Count   int
+	Percent float64 // Used when Count is zero
+}
+
+// DefaultThreshold flags items found on at most 10% of the agents
+var DefaultThreshold = Threshold{Percent: 10}
+
+// ParseThreshold parses a percentage such as "5%" or an agent count such
+// as "3"
+func ParseThreshold(s string) (Threshold, error) {
+	s = strings.TrimSpace(s)
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || f <= 0 || f > 100 {
+			return Threshold{}, fmt.Errorf("invalid anomaly threshold %q: use a percentage above 0%% and up to 100%%", s)
+		}
+		return Threshold{Percent: f}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return Threshold{}, fmt.Errorf("invalid anomaly threshold %q: use an agent count such as 3 or a percentage such as 5%%", s)
+	}
+	return Threshold{Count: n}, nil
+}
+
+// String formats the threshold as accepted by ParseThreshold
+func (t Threshold) String() string {
+	if t.Count > 0 {
+		return strconv.Itoa(t.Count)
+	}
+	return strconv.FormatFloat(t.Percent, 'f', -1, 64) + "%"
+}
+
+// Limit returns the highest agent count that is an anomaly among total
+// agents; an item on a single agent always is one
+func (t Threshold) Limit(total int) int {
+	if t.Count > 0 {
+		return t.Count
+	}
+	return max(int(math.Floor(float64(total)*t.Percent/100)), 1)
+}
+
+// Rarity scores how rare an item found on count of total agents is, as
+// log2(total/count): 0 when every agent has it, and one more each time the
+// share of agents halves
+func Rarity(count, total int) float64 {
+	if count <= 0 || total <= count {
+		return 0
+	}
+	return math.Log2(float64(total) / float64(count))
+}
+
+// Stacker counts the agents that have each combination of values of the
+// stacked columns, for frequency-of-occurrence analysis of flat rows
+type Stacker struct {
+	columns []string
+	stacks  []*Aggregate
+	index   map[string]*Aggregate
+}
+
+// NewStacker creates an empty stacker for the stacked columns
+func NewStacker(columns []string) *Stacker {
+	return &Stacker{columns: columns, index: make(map[string]*Aggregate)}
+}
+
+// Add counts one row
+func (s *Stacker) Add(agentID, agentName string, data map[string]Value) {
+	key := GroupKey(s.columns, data)
+	agg := s.index[key]
+	if agg == nil {
+		agg = &Aggregate{Path: Path{key}, Label: stackLabel(s.columns, data), Leaf: true, seen: make(map[string]bool)}
+		if len(s.columns) > 0 {
+			agg.Value = data[s.columns[0]]
+		}
+		s.index[key] = agg
+		s.stacks = append(s.stacks, agg)
+	}
+	if !agg.seen[agentID] {
+		agg.seen[agentID] = true
+		agg.AgentIDs = append(agg.AgentIDs, agentID)
+		agg.AgentNames = append(agg.AgentNames, agentName)
+	}
+}
+
+// Len returns the number of distinct combinations
+func (s *Stacker) Len() int {
+	return len(s.stacks)
+}
+
+// Stacks returns one aggregate per combination, ordered by agent count
+func (s *Stacker) Stacks(rarestFirst bool) []*Aggregate {
+	sortAggregates(s.stacks, rarestFirst)
+	return s.stacks
+}
+
+// stackLabel labels a combination as "Name: chrome, Version: 120"
+func stackLabel(columns []string, data map[string]Value) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		value, ok := data[col]
+		text := value.String()
+		switch {
+		case !ok:
+			text = "(missing)"
+		case value.IsNull():
+			text = "null"
+		}
+		parts[i] = col + ": " + text
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,134 @@
+package resultset
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Threshold
+		wantErr bool
+	}{
+		{in: "5%", want: Threshold{Percent: 5}},
+		{in: " 12.5 %", want: Threshold{Percent: 12.5}},
+		{in: "100%", want: Threshold{Percent: 100}},
+		{in: "3", want: Threshold{Count: 3}},
+		{in: "0%", wantErr: true},
+		{in: "101%", wantErr: true},
+		{in: "-5%", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "2.5", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "five", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseThreshold(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseThreshold(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseThreshold(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if err == nil {
+			// String round-trips
+			if again, err := ParseThreshold(got.String()); err != nil || again != got {
+				t.Errorf("ParseThreshold(%q.String()) = %+v, %v", tt.in, again, err)
+			}
+		}
+	}
+}
+
+func TestThresholdLimit(t *testing.T) {
+	tests := []struct {
+		threshold Threshold
+		total     int
+		want      int
+	}{
+		{Threshold{Percent: 10}, 100, 10},
+		{Threshold{Percent: 5}, 250, 12},
+		// Small fleets still flag items on a single agent
+		{Threshold{Percent: 10}, 5, 1},
+		{Threshold{Percent: 10}, 1, 1},
+		{Threshold{Percent: 10}, 0, 1},
+		{Threshold{Percent: 100}, 4, 4},
+		{Threshold{Count: 3}, 2, 3},
+		{Threshold{Count: 3}, 1000, 3},
+	}
+	for _, tt := range tests {
+		if got := tt.threshold.Limit(tt.total); got != tt.want {
+			t.Errorf("%s.Limit(%d) = %d, want %d", tt.threshold, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestRarity(t *testing.T) {
+	tests := []struct {
+		count, total int
+		want         float64
+	}{
+		{8, 8, 0},
+		{4, 8, 1},
+		{1, 8, 3},
+		{3, 12, 2},
+		{0, 8, 0},
+		{9, 8, 0},
+	}
+	for _, tt := range tests {
+		if got := Rarity(tt.count, tt.total); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Rarity(%d, %d) = %v, want %v", tt.count, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestStacker(t *testing.T) {
+	rows := []struct {
+		agentID, agentName string
+		data               map[string]Value
+	}{
+		{"1", "web-01", map[string]Value{"Name": StringValue("chrome"), "Version": NewValue(120.0)}},
+		{"2", "web-02", map[string]Value{"Name": StringValue("chrome"), "Version": NewValue(120.0)}},
+		// Twice on one agent counts the agent once
+		{"2", "web-02", map[string]Value{"Name": StringValue("chrome"), "Version": NewValue(120.0)}},
+		{"3", "db-01", map[string]Value{"Name": StringValue("chrome"), "Version": NewValue(96.0)}},
+		{"3", "db-01", map[string]Value{"Name": StringValue("putty")}},
+		{"1", "web-01", map[string]Value{"Name": StringValue("putty"), "Version": NewValue(nil)}},
+	}
+
+	s := NewStacker([]string{"Name", "Version"})
+	for _, row := range rows {
+		s.Add(row.agentID, row.agentName, row.data)
+	}
+	if s.Len() != 4 {
+		t.Errorf("Len() = %d, want 4 combinations", s.Len())
+	}
+
+	var labels []string
+	counts := make(map[string]int)
+	for _, stack := range s.Stacks(false) {
+		labels = append(labels, stack.Label)
+		counts[stack.Label] = stack.Count()
+	}
+	if labels[0] != "Name: chrome, Version: 120" {
+		t.Errorf("most common = %q, want chrome 120", labels[0])
+	}
+	want := map[string]int{
+		"Name: chrome, Version: 120":      2,
+		"Name: chrome, Version: 96":       1,
+		"Name: putty, Version: (missing)": 1,
+		"Name: putty, Version: null":      1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	rarest := s.Stacks(true)
+	if last := rarest[len(rarest)-1]; last.Label != "Name: chrome, Version: 120" || !reflect.DeepEqual(last.AgentNames, []string{"web-01", "web-02"}) {
+		t.Errorf("rarest first ends with %q on %v", last.Label, last.AgentNames)
+	}
+}
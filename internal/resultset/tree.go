@@ -0,0 +1,242 @@
+package resultset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Node is an element of an answer: an object key, an array item or a leaf value.
+// Nodes with the same path in different answers are the same item.
+type Node struct {
+	Key      string // Identifies the node among its siblings
+	Label    string // Display text
+	Leaf     bool
+	Value    interface{} // Leaf value
+	Children []*Node
+}
+
+// Tree converts parsed answer data to nodes. Object keys are visited in sorted
+// order; array items are named after their name/label/path/id field when they
+// have one.
+func Tree(data interface{}) []*Node {
+	var nodes []*Node
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			switch child := v[key].(type) {
+			case map[string]interface{}, []interface{}:
+				nodes = append(nodes, &Node{Key: key, Label: key, Children: Tree(child)})
+			default:
+				// Leaf value
+				label := fmt.Sprintf("%s: %s", key, FormatScalar(child))
+				nodes = append(nodes, &Node{Key: label, Label: label, Leaf: true, Value: child})
+			}
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			index := fmt.Sprintf("[%d]", i)
+
+			switch child := item.(type) {
+			case map[string]interface{}:
+				// Try to find a good label from the map
+				label := FindLabel(child)
+				if label == "" {
+					label = index
+				}
+				nodes = append(nodes, &Node{Key: label, Label: label, Children: Tree(child)})
+			case []interface{}:
+				label := fmt.Sprintf("[%d] (%d items)", i, len(child))
+				nodes = append(nodes, &Node{Key: index, Label: label, Children: Tree(child)})
+			default:
+				label := fmt.Sprintf("[%d]: %s", i, FormatScalar(child))
+				nodes = append(nodes, &Node{Key: label, Label: label, Leaf: true, Value: child})
+			}
+		}
+	}
+
+	return nodes
+}
+
+// CountNodes counts nodes including all descendants
+func CountNodes(nodes []*Node) int {
+	count := len(nodes)
+	for _, node := range nodes {
+		count += CountNodes(node.Children)
+	}
+	return count
+}
+
+// FindLabel tries to find a good label from a map
+func FindLabel(m map[string]interface{}) string {
+	// Common label keys
+	labelKeys := []string{"name", "Name", "label", "Label", "title", "Title", "path", "Path", "key", "Key", "id", "Id", "ID"}
+	for _, key := range labelKeys {
+		if val, ok := m[key]; ok {
+			if s, ok := val.(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// FormatScalar formats a leaf value: numbers keep their JSON text and null is "null"
+func FormatScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// HasNestedStructure checks if data contains nested objects/arrays
+func HasNestedStructure(data interface{}) bool {
+	switch v := data.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			return false
+		}
+		// Check if array items have nested objects/arrays
+		if first, ok := v[0].(map[string]interface{}); ok {
+			for _, val := range first {
+				switch val.(type) {
+				case map[string]interface{}, []interface{}:
+					return true
+				}
+			}
+			// Also check for self-referential fields (parent/child)
+			return hasSelfReferentialFields(v)
+		}
+	case map[string]interface{}:
+		for _, val := range v {
+			switch val.(type) {
+			case map[string]interface{}, []interface{}:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasSelfReferentialFields detects self-referential tree structure by analyzing data values
+// This matches the web frontend's approach: look for a field where values reference another field's values
+func hasSelfReferentialFields(rows []interface{}) bool {
+	if len(rows) < 2 {
+		return false
+	}
+
+	// Convert to maps
+	var rowMaps []map[string]interface{}
+	for _, r := range rows {
+		if m, ok := r.(map[string]interface{}); ok {
+			rowMaps = append(rowMaps, m)
+		}
+	}
+
+	if len(rowMaps) < 2 {
+		return false
+	}
+
+	// Get column names from first row
+	columns := make([]string, 0)
+	for k := range rowMaps[0] {
+		columns = append(columns, k)
+	}
+
+	if len(columns) < 2 {
+		return false
+	}
+
+	// Try each pair of columns to find id/parent relationship
+	for _, idCol := range columns {
+		// Collect all values in the potential ID column (as strings to avoid unhashable types)
+		idValues := make(map[string]bool)
+		for _, row := range rowMaps {
+			if val, ok := row[idCol]; ok && val != nil {
+				// Only use scalar values (strings, numbers) - skip maps and slices
+				strVal := toStringKey(val)
+				if strVal != "" {
+					idValues[strVal] = true
+				}
+			}
+		}
+
+		// Skip if no valid IDs
+		if len(idValues) == 0 {
+			continue
+		}
+
+		for _, parentCol := range columns {
+			if idCol == parentCol {
+				continue
+			}
+
+			// Count valid references:
+			// - null/nil/empty/0 = root node (valid)
+			// - references an existing ID (valid)
+			validRefs := 0
+			hasRoot := false
+
+			for _, row := range rowMaps {
+				parentVal := row[parentCol]
+
+				// Check for root indicators
+				if parentVal == nil {
+					validRefs++
+					hasRoot = true
+				} else {
+					strVal := toStringKey(parentVal)
+					if strVal == "" || strVal == "0" {
+						validRefs++
+						hasRoot = true
+					} else if idValues[strVal] {
+						validRefs++
+					}
+				}
+			}
+
+			// Must have: 80%+ valid references AND at least one root
+			refRatio := float64(validRefs) / float64(len(rowMaps))
+			if refRatio >= 0.8 && hasRoot {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// toStringKey converts a value to a string key, returning empty string for non-scalar types
+func toStringKey(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return fmt.Sprintf("%v", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	case int64:
+		return fmt.Sprintf("%d", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	default:
+		// Maps, slices, etc. - not usable as keys
+		return ""
+	}
+}
@@ -0,0 +1,87 @@
+package resultset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, answer string) interface{} {
+	t.Helper()
+	data, err := Parse(answer)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", answer, err)
+	}
+	return data
+}
+
+func pathStrings(paths []Path) []string {
+	strs := make([]string, len(paths))
+	for i, p := range paths {
+		strs[i] = p.String()
+	}
+	return strs
+}
+
+func TestTreePaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   []string
+	}{
+		{
+			name:   "object keys sorted",
+			answer: `{"b": 1, "a": {"c": true}}`,
+			want:   []string{"/a", "/a/c: true", "/b: 1"},
+		},
+		{
+			name:   "array items named by label field",
+			answer: `[{"Name": "chrome", "Version": "120"}, {"Name": "firefox"}]`,
+			want: []string{
+				"/chrome", "/chrome/Name: chrome", "/chrome/Version: 120",
+				"/firefox", "/firefox/Name: firefox",
+			},
+		},
+		{
+			name:   "array items without label use the index",
+			answer: `[{"size": 10}, [1, 2], "x"]`,
+			want:   []string{"/[0]", "/[0]/size: 10", "/[1]", "/[1]/[0]: 1", "/[1]/[1]: 2", "/[2]: x"},
+		},
+		{
+			name:   "null leaf",
+			answer: `{"owner": null}`,
+			want:   []string{"/owner: null"},
+		},
+		{
+			name:   "path label keeps separators escaped",
+			answer: `[{"Path": "/usr/bin"}]`,
+			want:   []string{`/\/usr\/bin`, `/\/usr\/bin/Path: \/usr\/bin`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := Tree(mustParse(t, tt.answer))
+			got := pathStrings(Paths(nodes))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paths = %q, want %q", got, tt.want)
+			}
+			if CountNodes(nodes) != len(tt.want) {
+				t.Errorf("CountNodes = %d, want %d", CountNodes(nodes), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestTreeLeafValues(t *testing.T) {
+	nodes := Tree(mustParse(t, `{"name": "svchost", "pid": 4}`))
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2", len(nodes))
+	}
+	for _, node := range nodes {
+		if !node.Leaf || len(node.Children) != 0 {
+			t.Errorf("node %q is not a leaf", node.Key)
+		}
+	}
+	if nodes[0].Value != "svchost" {
+		t.Errorf("name value = %v", nodes[0].Value)
+	}
+}
@@ -0,0 +1,212 @@
+package resultset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a value, or the inferred type of a column
+type Kind int
+
+// Kinds are ordered: values of different kinds compare by kind
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindTime
+	KindString
+	KindList
+)
+
+// String returns the kind's name
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindTime:
+		return "time"
+	case KindList:
+		return "list"
+	default:
+		return "string"
+	}
+}
+
+// Value is a flattened answer field that keeps its JSON type. The zero Value
+// is a missing field, which has KindNull but is not a JSON null.
+type Value struct {
+	Kind Kind
+	null bool
+	text string
+	num  float64
+	time time.Time
+	raw  interface{}
+}
+
+// NewValue converts a decoded JSON value. Strings holding a timestamp with a
+// time zone, or a .NET "/Date(ms)/" as written by ConvertTo-Json, become
+// times; timestamps without a zone stay strings because the agent's zone is
+// unknown.
+func NewValue(value interface{}) Value {
+	switch v := value.(type) {
+	case nil:
+		return Value{Kind: KindNull, null: true}
+	case bool:
+		return Value{Kind: KindBool, text: strconv.FormatBool(v), raw: v}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil && !math.IsInf(f, 0) {
+			return StringValue(v.String())
+		}
+		return Value{Kind: KindNumber, text: v.String(), num: f, raw: v}
+	case float64:
+		return Value{Kind: KindNumber, text: strconv.FormatFloat(v, 'f', -1, 64), num: v, raw: v}
+	case string:
+		if t, ok := parseTime(v); ok {
+			return Value{Kind: KindTime, text: v, time: t, raw: v}
+		}
+		return StringValue(v)
+	case []interface{}:
+		return Value{Kind: KindList, text: formatList(v), num: float64(len(v)), raw: v}
+	default:
+		return StringValue(fmt.Sprintf("%v", v))
+	}
+}
+
+// StringValue returns a string value
+func StringValue(s string) Value {
+	return Value{Kind: KindString, text: s, raw: s}
+}
+
+// String returns the value as text: numbers keep their JSON text, times their
+// original text and null is empty
+func (v Value) String() string {
+	return v.text
+}
+
+// Float returns the value of a number, or the item count of a list
+func (v Value) Float() float64 {
+	return v.num
+}
+
+// Time returns the value of a time
+func (v Value) Time() time.Time {
+	return v.time
+}
+
+// IsNull reports whether the value is a JSON null rather than missing
+func (v Value) IsNull() bool {
+	return v.null
+}
+
+// Interface returns the value as decoded from JSON, for encoding it again
+func (v Value) Interface() interface{} {
+	return v.raw
+}
+
+// Compare orders two values: nulls first, numbers and list lengths
+// numerically, times chronologically, false before true, and strings
+// case-insensitively. Values of different kinds are ordered by kind.
+func Compare(a, b Value) int {
+	if a.Kind != b.Kind {
+		if a.Kind < b.Kind {
+			return -1
+		}
+		return 1
+	}
+
+	switch a.Kind {
+	case KindNull:
+		return 0
+	case KindNumber, KindList:
+		if a.num != b.num {
+			if a.num < b.num {
+				return -1
+			}
+			return 1
+		}
+	case KindTime:
+		if c := a.time.Compare(b.time); c != 0 {
+			return c
+		}
+	case KindBool:
+		if a.text != b.text {
+			if a.text == "false" {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	if c := strings.Compare(strings.ToLower(a.text), strings.ToLower(b.text)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+// MergeKind combines the kind inferred for a column so far with the kind of
+// another of its values. Nulls do not change the kind; a column with values
+// of different kinds is a string column.
+func MergeKind(column, value Kind) Kind {
+	switch {
+	case value == KindNull || column == value:
+		return column
+	case column == KindNull:
+		return value
+	default:
+		return KindString
+	}
+}
+
+// formatList shows short lists inline and longer ones as an item count
+func formatList(items []interface{}) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	if len(items) > 3 {
+		return fmt.Sprintf("[%d items]", len(items))
+	}
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = fmt.Sprintf("%v", item)
+	}
+	return strings.Join(texts, ", ")
+}
+
+// dotNetDate matches the DateTime format of PowerShell 5's ConvertTo-Json
+var dotNetDate = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+// timeLayouts are timestamp layouts that include a time zone
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+}
+
+func parseTime(s string) (time.Time, bool) {
+	// Cheap check before trying layouts: every format starts with a digit or "/"
+	if len(s) < 10 || (s[0] != '/' && (s[0] < '0' || s[0] > '9')) {
+		return time.Time{}, false
+	}
+	if m := dotNetDate.FindStringSubmatch(s); m != nil {
+		ms, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(ms), true
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -65,11 +65,14 @@ func StandardHelpItems() []HelpItem {
 // TableViewHelpItems returns help items for table view
 func TableViewHelpItems() []HelpItem {
 	return []HelpItem{
-		{Key: "↑↓", Desc: "Navigate"},
+		{Key: "↑↓←→", Desc: "Navigate/Column"},
+		{Key: "s/S", Desc: "Sort/Add Sort"},
+		{Key: "c", Desc: "Columns"},
 		{Key: "Enter", Desc: "Details"},
+		{Key: "x/R", Desc: "Mark/Run"},
 		{Key: "/", Desc: "Search"},
 		{Key: "Tab", Desc: "Tabs"},
-		{Key: "1/2/3", Desc: "View"},
+		{Key: "1-4", Desc: "View"},
 		{Key: "q", Desc: "Quit"},
 	}
 }
@@ -79,9 +82,10 @@ func TreeViewHelpItems() []HelpItem {
 	return []HelpItem{
 		{Key: "↑↓←→", Desc: "Navigate/Expand"},
 		{Key: "e/c", Desc: "Expand/Collapse All"},
+		{Key: "x/R", Desc: "Mark/Run"},
 		{Key: "/", Desc: "Search"},
 		{Key: "Tab", Desc: "Tabs"},
-		{Key: "1/2/3", Desc: "View"},
+		{Key: "1-4", Desc: "View"},
 		{Key: "q", Desc: "Quit"},
 	}
 }
@@ -91,9 +95,60 @@ func AggregatedViewHelpItems() []HelpItem {
 	return []HelpItem{
 		{Key: "↑↓←→", Desc: "Navigate/Expand"},
 		{Key: "a", Desc: "Anomalies"},
+		{Key: "A", Desc: "Agents"},
+		{Key: "g", Desc: "Stack By"},
+		{Key: "o", Desc: "Rarest First"},
+		{Key: "x/R", Desc: "Mark/Run"},
+		{Key: "/", Desc: "Search"},
+		{Key: "Tab", Desc: "Tabs"},
+		{Key: "1-4", Desc: "View"},
+		{Key: "q", Desc: "Quit"},
+	}
+}
+
+// ColumnPickerHelpItems returns help items for the column picker
+func ColumnPickerHelpItems() []HelpItem {
+	return []HelpItem{
+		{Key: "↑↓", Desc: "Navigate"},
+		{Key: "Space", Desc: "Show/Hide"},
+		{Key: "K/J", Desc: "Move"},
+		{Key: "a/r", Desc: "All/Reset"},
+		{Key: "Enter", Desc: "Apply"},
+		{Key: "Esc", Desc: "Cancel"},
+	}
+}
+
+// AgentPanelHelpItems returns help items for the agent panel of an
+// aggregated node
+func AgentPanelHelpItems() []HelpItem {
+	return []HelpItem{
+		{Key: "↑↓", Desc: "Navigate"},
+		{Key: "t", Desc: "With/Without"},
+		{Key: "x", Desc: "Mark"},
+		{Key: "Enter", Desc: "Agent's Results"},
+		{Key: "Esc", Desc: "Close"},
+	}
+}
+
+// FollowUpHelpItems returns help items for launching a follow-up execution
+func FollowUpHelpItems() []HelpItem {
+	return []HelpItem{
+		{Key: "↑↓", Desc: "Navigate"},
+		{Key: "Type", Desc: "Filter/Edit"},
+		{Key: "Enter", Desc: "Next/Run"},
+		{Key: "Esc", Desc: "Back"},
+	}
+}
+
+// GroupViewHelpItems returns help items for group view
+func GroupViewHelpItems() []HelpItem {
+	return []HelpItem{
+		{Key: "↑↓", Desc: "Navigate"},
+		{Key: "Enter", Desc: "Rows"},
+		{Key: "g", Desc: "Group By"},
 		{Key: "/", Desc: "Search"},
 		{Key: "Tab", Desc: "Tabs"},
-		{Key: "1/2/3", Desc: "View"},
+		{Key: "1-4", Desc: "View"},
 		{Key: "q", Desc: "Quit"},
 	}
 }
@@ -85,38 +85,44 @@ func (t *TabBar) Render() string {
 	return strings.Join(tabs, "  ")
 }
 
-// ViewModeBar renders the view mode selector (Table/Tree/Aggregated)
+// ViewModeBar renders the view mode selector (Table/Tree/Aggregated/Group)
 type ViewModeBar struct {
 	modes       []string
 	keys        []string // keyboard shortcuts
 	activeIdx   int
-	treeEnabled bool // whether Tree/Aggregated modes are available
+	treeEnabled bool // whether the Tree mode is available
 }
 
 // NewViewModeBar creates a view mode bar with the standard modes
 func NewViewModeBar() *ViewModeBar {
 	return &ViewModeBar{
-		modes:       []string{"Table", "Tree", "Aggregated"},
-		keys:        []string{"1", "2", "3"},
+		modes:       []string{"Table", "Tree", "Aggregated", "Group"},
+		keys:        []string{"1", "2", "3", "4"},
 		activeIdx:   0,
 		treeEnabled: true,
 	}
 }
 
-// SetTreeEnabled enables or disables Tree/Aggregated modes
+// SetTreeEnabled enables or disables the Tree mode. The Aggregated mode
+// stacks flat rows by column, so it stays available.
 func (v *ViewModeBar) SetTreeEnabled(enabled bool) {
 	v.treeEnabled = enabled
 	// If tree is disabled and we're in a tree mode, switch to table
-	if !enabled && v.activeIdx > 0 {
+	if !enabled && isTreeMode(v.activeIdx) {
 		v.activeIdx = 0
 	}
 }
 
+// isTreeMode reports whether the mode at idx needs hierarchical data
+func isTreeMode(idx int) bool {
+	return idx == 1
+}
+
 // SetActive sets the active view mode by index
 func (v *ViewModeBar) SetActive(idx int) {
 	if idx >= 0 && idx < len(v.modes) {
 		// Don't allow setting tree modes if disabled
-		if !v.treeEnabled && idx > 0 {
+		if !v.treeEnabled && isTreeMode(idx) {
 			return
 		}
 		v.activeIdx = idx
@@ -140,19 +146,16 @@ func (v *ViewModeBar) IsTreeEnabled() bool {
 
 // Render returns the rendered view mode bar
 func (v *ViewModeBar) Render() string {
-	// If tree modes are disabled, only show Table
-	if !v.treeEnabled {
-		return lipgloss.NewStyle().
-			Foreground(ui.Muted).
-			Render("View: ") + ui.ViewModeActiveStyle.Render("[1]Table")
-	}
-
 	viewLabel := lipgloss.NewStyle().
 		Foreground(ui.Muted).
 		Render("View: ")
 
 	var modes []string
 	for i, mode := range v.modes {
+		// If the tree mode is disabled, hide it
+		if !v.treeEnabled && isTreeMode(i) {
+			continue
+		}
 		key := v.keys[i]
 		if i == v.activeIdx {
 			// Active mode: highlighted with brackets
@@ -20,7 +20,9 @@ type TreeNode struct {
 	Count      int      // Number of agents with this node
 	TotalCount int      // Total number of agents
 	AgentNames []string // List of agent names
+	AgentIDs   []string // IDs of the agents, in the order of AgentNames
 	IsAnomaly  bool     // True if this node appears on few agents
+	Rarity     float64  // log2(TotalCount/Count): 0 on every agent, higher when rarer
 }
 
 // AgentTree represents a tree of results for a single agent
@@ -40,6 +42,7 @@ type TreeView struct {
 	viewportStart  int
 	viewportHeight int
 	width          int
+	marked         map[string]bool // Agents marked in the TUI, by ID
 }
 
 // flatNode represents a node in the flattened tree for navigation
@@ -70,6 +73,11 @@ func (t *TreeView) SetAgents(agents []*AgentTree) {
 	t.rebuildFlatList()
 }
 
+// SetMarked sets the agents whose headers are marked
+func (t *TreeView) SetMarked(agentIDs map[string]bool) {
+	t.marked = agentIDs
+}
+
 // SetViewportHeight sets the number of visible lines
 func (t *TreeView) SetViewportHeight(height int) {
 	t.viewportHeight = height
@@ -269,9 +277,14 @@ func (t *TreeView) renderNode(fn *flatNode, isSelected bool) string {
 			expandChar = ui.TreeExpanded
 		}
 
+		agentName := fn.agent.AgentName
+		if t.marked[fn.agent.AgentID] {
+			agentName = ui.MarkedAgent + " " + agentName
+		}
+
 		agentLine := fmt.Sprintf("%s %s (%d items)",
 			expandChar,
-			fn.agent.AgentName,
+			agentName,
 			fn.agent.NodeCount,
 		)
 
@@ -279,7 +292,7 @@ func (t *TreeView) renderNode(fn *flatNode, isSelected bool) string {
 			line = ui.SelectedStyle.Render(agentLine)
 		} else {
 			line = ui.TreeExpandedStyle.Render(expandChar) + " " +
-				ui.HeaderStyle.Render(fn.agent.AgentName) + " " +
+				ui.HeaderStyle.Render(agentName) + " " +
 				ui.MutedStyle.Render(fmt.Sprintf("(%d items)", fn.agent.NodeCount))
 		}
 	} else {
@@ -300,7 +313,7 @@ func (t *TreeView) renderNode(fn *flatNode, isSelected bool) string {
 		// For aggregated view, add count badge
 		var countBadge string
 		if fn.node.TotalCount > 0 {
-			countBadge = fmt.Sprintf(" [%d/%d]", fn.node.Count, fn.node.TotalCount)
+			countBadge = fmt.Sprintf(" [%d/%d r%.1f]", fn.node.Count, fn.node.TotalCount, fn.node.Rarity)
 		}
 
 		// Truncate if too long
@@ -395,3 +408,115 @@ func (t *TreeView) GetSelectedAgent() *AgentTree {
 func (t *TreeView) GetAgents() []*AgentTree {
 	return t.agents
 }
+
+// nodeKey identifies a node across rebuilds of the same data
+func nodeKey(agent *AgentTree, node *TreeNode) string {
+	if node == nil {
+		return "agent:" + agent.AgentID
+	}
+	return agent.AgentID + "/" + node.ID
+}
+
+// ExpansionState returns the expanded flag of every agent and node, keyed by ID
+func (t *TreeView) ExpansionState() map[string]bool {
+	state := make(map[string]bool)
+	var walk func(agent *AgentTree, nodes []*TreeNode)
+	walk = func(agent *AgentTree, nodes []*TreeNode) {
+		for _, n := range nodes {
+			if len(n.Children) > 0 {
+				state[nodeKey(agent, n)] = n.Expanded
+				walk(agent, n.Children)
+			}
+		}
+	}
+	for _, agent := range t.agents {
+		state[nodeKey(agent, nil)] = agent.Expanded
+		walk(agent, agent.Roots)
+	}
+	return state
+}
+
+// RestoreExpansion applies a state from ExpansionState.
+// Agents and nodes not present in the state keep their current flag.
+func (t *TreeView) RestoreExpansion(state map[string]bool) {
+	var walk func(agent *AgentTree, nodes []*TreeNode)
+	walk = func(agent *AgentTree, nodes []*TreeNode) {
+		for _, n := range nodes {
+			if expanded, ok := state[nodeKey(agent, n)]; ok {
+				n.Expanded = expanded
+			}
+			walk(agent, n.Children)
+		}
+	}
+	for _, agent := range t.agents {
+		if expanded, ok := state[nodeKey(agent, nil)]; ok {
+			agent.Expanded = expanded
+		}
+		walk(agent, agent.Roots)
+	}
+	t.rebuildFlatList()
+}
+
+// Reveal expands an agent and the parents of one of its nodes, then selects
+// the node. If the node is not found the agent is selected instead; Reveal
+// reports whether the agent was found.
+func (t *TreeView) Reveal(agentID, nodeID string) bool {
+	for _, agent := range t.agents {
+		if agent.AgentID != agentID {
+			continue
+		}
+		agent.Expanded = true
+		node := expandPathTo(agent.Roots, nodeID)
+		t.rebuildFlatList()
+		if node == nil || !t.SelectByID(nodeKey(agent, node)) {
+			t.SelectByID(nodeKey(agent, nil))
+		}
+		return true
+	}
+	return false
+}
+
+// expandPathTo finds a node by ID and expands its parents
+func expandPathTo(nodes []*TreeNode, id string) *TreeNode {
+	for _, n := range nodes {
+		if n.ID == id {
+			return n
+		}
+		if found := expandPathTo(n.Children, id); found != nil {
+			n.Expanded = true
+			return found
+		}
+	}
+	return nil
+}
+
+// SelectedID returns an identifier for the selected line, or "" if empty
+func (t *TreeView) SelectedID() string {
+	if t.selectedIdx >= len(t.flatNodes) {
+		return ""
+	}
+	fn := t.flatNodes[t.selectedIdx]
+	if fn.isAgent {
+		return nodeKey(fn.agent, nil)
+	}
+	return nodeKey(fn.agent, fn.node)
+}
+
+// SelectByID selects the line with the given SelectedID, reporting whether it was found
+func (t *TreeView) SelectByID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for i, fn := range t.flatNodes {
+		key := nodeKey(fn.agent, fn.node)
+		if fn.isAgent {
+			key = nodeKey(fn.agent, nil)
+		}
+		if key == id {
+			t.selectedIdx = i
+			t.ensureSelectedVisible()
+			return true
+		}
+	}
+	return false
+}
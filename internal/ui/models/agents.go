@@ -0,0 +1,246 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/ui"
+	"github.com/Binmave/binmave-cli/internal/ui/components"
+)
+
+// agentsMsg carries the agents of the fleet, for their status
+type agentsMsg struct {
+	agents []api.Agent
+	err    error
+}
+
+// panelAgent is an agent listed in the agent panel
+type panelAgent struct {
+	ID   string
+	Name string
+}
+
+// fetchAgents fetches every agent for the agent panel
+func (m *ResultsModel) fetchAgents() tea.Msg {
+	agents, err := m.client.ListAgents(m.ctx)
+	return agentsMsg{agents: agents, err: err}
+}
+
+// openAgentPanel lists the agents of the selected aggregated node, loading
+// agent status on first use
+func (m *ResultsModel) openAgentPanel() tea.Cmd {
+	node := m.treeView.GetSelectedNode()
+	if node == nil || node.TotalCount == 0 {
+		return nil
+	}
+	m.agentPanel = node
+	m.agentPanelIdx = 0
+	m.agentPanelScroll = 0
+	m.agentPanelLacking = false
+	m.helpBar.SetItems(components.AgentPanelHelpItems())
+
+	if m.fleet != nil || m.fleetLoading || m.client == nil {
+		return nil
+	}
+	m.fleetLoading = true
+	return m.fetchAgents
+}
+
+func (m *ResultsModel) closeAgentPanel() {
+	m.agentPanel = nil
+	m.updateHelpItems()
+}
+
+// panelAgents returns the agents that have the panel's node, or those of the
+// current tab that lack it, by name
+func (m *ResultsModel) panelAgents() []panelAgent {
+	node := m.agentPanel
+	has := make(map[string]bool, len(node.AgentIDs))
+	var agents []panelAgent
+	for i, id := range node.AgentIDs {
+		has[id] = true
+		if !m.agentPanelLacking {
+			agents = append(agents, panelAgent{ID: id, Name: node.AgentNames[i]})
+		}
+	}
+	if m.agentPanelLacking {
+		for _, r := range m.getCurrentResults() {
+			if !has[r.AgentID] {
+				has[r.AgentID] = true
+				agents = append(agents, panelAgent{ID: r.AgentID, Name: r.AgentName})
+			}
+		}
+	}
+
+	sort.SliceStable(agents, func(i, j int) bool {
+		return strings.ToLower(agents[i].Name) < strings.ToLower(agents[j].Name)
+	})
+	return agents
+}
+
+// updateAgentPanel handles keys while the agent panel is open
+func (m *ResultsModel) updateAgentPanel(msg tea.KeyMsg) tea.Cmd {
+	agents := m.panelAgents()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q", "A":
+		m.closeAgentPanel()
+	case "up", "k":
+		if m.agentPanelIdx > 0 {
+			m.agentPanelIdx--
+		}
+	case "down", "j":
+		if m.agentPanelIdx < len(agents)-1 {
+			m.agentPanelIdx++
+		}
+	case "x":
+		if m.agentPanelIdx < len(agents) {
+			m.toggleMarks(agents[m.agentPanelIdx : m.agentPanelIdx+1])
+		}
+	case "t", "tab":
+		m.agentPanelLacking = !m.agentPanelLacking
+		m.agentPanelIdx = 0
+		m.agentPanelScroll = 0
+	case "enter":
+		if m.agentPanelIdx < len(agents) {
+			m.jumpToAgent(agents[m.agentPanelIdx])
+		}
+	}
+	return nil
+}
+
+// jumpToAgent shows the results of one agent: its tree with the panel's node
+// revealed, or its rows in the table view when the results are flat
+func (m *ResultsModel) jumpToAgent(agent panelAgent) {
+	node := m.agentPanel
+	m.closeAgentPanel()
+
+	if m.isTreeData {
+		m.setViewMode(TreeView)
+		m.treeView.Reveal(agent.ID, node.ID)
+		return
+	}
+
+	m.drillAgent = &agent
+	m.tableSelectedIdx = 0
+	m.tableScrollOffset = 0
+	m.setViewMode(TableView)
+	m.applySearchFilter()
+}
+
+// leaveAgent goes back from one agent's rows to the rows of every agent
+func (m *ResultsModel) leaveAgent() {
+	m.drillAgent = nil
+	m.tableSelectedIdx = 0
+	m.tableScrollOffset = 0
+	m.applySearchFilter()
+}
+
+// renderAgentPanel renders the agents that have (or lack) the panel's node,
+// with their status from the agent list
+func (m *ResultsModel) renderAgentPanel(height int) string {
+	var b strings.Builder
+	node := m.agentPanel
+	agents := m.panelAgents()
+
+	title := "Agents with "
+	if m.agentPanelLacking {
+		title = "Agents without "
+	}
+	b.WriteString(ui.HeaderStyle.Render(title + truncateString(node.Label, max(m.width-40, 20))))
+	b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  (%d of %d agents)", len(agents), node.TotalCount)))
+	b.WriteString("\n")
+
+	switch {
+	case m.fleetLoading:
+		b.WriteString(m.spinner.View() + " Loading agent status...")
+	case m.fleetErr != nil:
+		b.WriteString(ui.MutedStyle.Render("Agent status unavailable: " + m.fleetErr.Error()))
+	}
+	b.WriteString("\n")
+
+	if len(agents) == 0 {
+		b.WriteString(ui.MutedStyle.Render("  No agents"))
+		return b.String()
+	}
+
+	nameWidth := 16
+	for _, agent := range agents {
+		nameWidth = max(nameWidth, len(agent.Name))
+	}
+	nameWidth = min(nameWidth, 40)
+	header := fmt.Sprintf("%-*s │ %-12s │ %-24s │ %s", nameWidth, "AGENT", "STATUS", "OS", "LAST IP")
+	b.WriteString(ui.HeaderStyle.Render(header))
+	b.WriteString("\n")
+	b.WriteString(ui.MutedStyle.Render(strings.Repeat("─", min(lipgloss.Width(header), m.width))))
+	b.WriteString("\n")
+
+	// Scroll to keep the selection visible
+	lines := max(height-4, 1)
+	if m.agentPanelIdx < m.agentPanelScroll {
+		m.agentPanelScroll = m.agentPanelIdx
+	}
+	if m.agentPanelIdx >= m.agentPanelScroll+lines {
+		m.agentPanelScroll = m.agentPanelIdx - lines + 1
+	}
+	end := min(m.agentPanelScroll+lines, len(agents))
+
+	for i := m.agentPanelScroll; i < end; i++ {
+		agent := agents[i]
+		info, known := m.fleet[agent.ID]
+		status := "unknown"
+		if known {
+			status = info.AgentStatus
+		}
+		name := fmt.Sprintf("%-*s", nameWidth, truncateString(agent.Name, nameWidth))
+		system := fmt.Sprintf("%-24s", truncateString(info.OperatingSystem, 24))
+
+		if i == m.agentPanelIdx {
+			line := fmt.Sprintf("%s │ %-12s │ %s │ %s", name, formatAgentStatus(status), system, info.LastIP)
+			b.WriteString(ui.SelectedStyle.Render(line))
+		} else {
+			b.WriteString(name + " │ " + renderAgentStatus(status) + " │ " + ui.MutedStyle.Render(system) + " │ " + ui.MutedStyle.Render(info.LastIP))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// formatAgentStatus marks an agent status with a symbol
+func formatAgentStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "online":
+		return "● Online"
+	case "offline":
+		return "○ Offline"
+	case "expired":
+		return "✗ Expired"
+	case "expiring":
+		return "! Expiring"
+	default:
+		return status
+	}
+}
+
+// renderAgentStatus colors a status padded to the STATUS column
+func renderAgentStatus(status string) string {
+	text := fmt.Sprintf("%-12s", formatAgentStatus(status))
+	switch strings.ToLower(status) {
+	case "online":
+		return ui.SuccessStyle.Render(text)
+	case "expired":
+		return ui.ErrorStyle.Render(text)
+	case "expiring":
+		return ui.WarningStyle.Render(text)
+	default:
+		return ui.MutedStyle.Render(text)
+	}
+}
@@ -2,7 +2,6 @@ package models
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -12,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/resultset"
 	"github.com/Binmave/binmave-cli/internal/ui"
 	"github.com/Binmave/binmave-cli/internal/ui/components"
 )
@@ -40,7 +40,7 @@ type DiffItem struct {
 type CompareModel struct {
 	executionID string
 	baselineID  string
-	client      *api.Client
+	client      api.Backend
 	ctx         context.Context
 
 	// Data
@@ -62,6 +62,7 @@ type CompareModel struct {
 	scrollOffset  int
 	err           error
 	dataReady     int // 0 = nothing, 1 = partial, 2 = all loaded
+	fetchProgress map[string]api.FetchProgress
 
 	// Components
 	helpBar *components.HelpBar
@@ -87,7 +88,7 @@ type compareCurrentMsg struct {
 }
 
 // NewCompareModel creates a new compare TUI model
-func NewCompareModel(executionID, baselineID string, client *api.Client) *CompareModel {
+func NewCompareModel(executionID, baselineID string, client api.Backend) *CompareModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(ui.Primary)
@@ -101,6 +102,7 @@ func NewCompareModel(executionID, baselineID string, client *api.Client) *Compar
 		client:        client,
 		ctx:           context.Background(),
 		loading:       true,
+		fetchProgress: make(map[string]api.FetchProgress),
 		showDiffsOnly: true,
 		helpBar:       helpBar,
 		spinner:       s,
@@ -113,30 +115,30 @@ func NewCompareModel(executionID, baselineID string, client *api.Client) *Compar
 func (m *CompareModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		m.fetchBaseline,
-		m.fetchCurrent,
+		fetchWithProgress(m.baselineID, m.fetchBaseline),
+		fetchWithProgress(m.executionID, m.fetchCurrent),
 	)
 }
 
 // fetchBaseline fetches baseline execution and results
-func (m *CompareModel) fetchBaseline() tea.Msg {
+func (m *CompareModel) fetchBaseline(progress api.FetchOption) tea.Msg {
 	execution, err := m.client.GetExecution(m.ctx, m.baselineID)
 	if err != nil {
 		return compareBaselineMsg{err: err}
 	}
 
-	results, err := m.client.GetAllExecutionResults(m.ctx, m.baselineID)
+	results, err := api.GetAllExecutionResults(m.ctx, m.client, m.baselineID, progress)
 	return compareBaselineMsg{execution: execution, results: results, err: err}
 }
 
 // fetchCurrent fetches current execution and results
-func (m *CompareModel) fetchCurrent() tea.Msg {
+func (m *CompareModel) fetchCurrent(progress api.FetchOption) tea.Msg {
 	execution, err := m.client.GetExecution(m.ctx, m.executionID)
 	if err != nil {
 		return compareCurrentMsg{err: err}
 	}
 
-	results, err := m.client.GetAllExecutionResults(m.ctx, m.executionID)
+	results, err := api.GetAllExecutionResults(m.ctx, m.client, m.executionID, progress)
 	return compareCurrentMsg{execution: execution, results: results, err: err}
 }
 
@@ -185,6 +187,10 @@ func (m *CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
+	case fetchProgressMsg:
+		m.fetchProgress[msg.executionID] = msg.progress
+		cmds = append(cmds, waitForFetchProgress(msg.executionID, msg.ch))
+
 	case compareBaselineMsg:
 		if msg.err != nil {
 			m.err = fmt.Errorf("baseline: %w", msg.err)
@@ -209,6 +215,21 @@ func (m *CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// loadingProgress describes page progress of both fetches while loading
+func (m *CompareModel) loadingProgress() string {
+	var parts []string
+	if pages := formatFetchProgress(m.fetchProgress[m.baselineID]); pages != "" {
+		parts = append(parts, "baseline "+pages)
+	}
+	if pages := formatFetchProgress(m.fetchProgress[m.executionID]); pages != "" {
+		parts = append(parts, "current "+pages)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, ", ")
+}
+
 // tryComputeDiffs computes diffs once both datasets are loaded
 func (m *CompareModel) tryComputeDiffs() {
 	if m.dataReady < 2 {
@@ -229,25 +250,21 @@ func (m *CompareModel) computeDiffs() {
 	baselinePaths := make(map[string]map[string]bool) // path -> agentID -> exists
 	currentPaths := make(map[string]map[string]bool)
 
-	for _, r := range m.baselineResults {
-		paths := extractPaths(r.AnswerJSON)
-		for _, p := range paths {
-			if baselinePaths[p] == nil {
-				baselinePaths[p] = make(map[string]bool)
-			}
-			baselinePaths[p][r.AgentName] = true
-		}
-	}
-
-	for _, r := range m.currentResults {
-		paths := extractPaths(r.AnswerJSON)
-		for _, p := range paths {
-			if currentPaths[p] == nil {
-				currentPaths[p] = make(map[string]bool)
+	labels := make(map[string]string)
+	collect := func(results []api.ExecutionResult, paths map[string]map[string]bool) {
+		for _, r := range results {
+			for _, p := range extractPaths(r.AnswerJSON) {
+				key := p.String()
+				if paths[key] == nil {
+					paths[key] = make(map[string]bool)
+				}
+				paths[key][r.AgentName] = true
+				labels[key] = p.Last()
 			}
-			currentPaths[p][r.AgentName] = true
 		}
 	}
+	collect(m.baselineResults, baselinePaths)
+	collect(m.currentResults, currentPaths)
 
 	// Find new items (in current but not baseline)
 	for path, agents := range currentPaths {
@@ -255,7 +272,7 @@ func (m *CompareModel) computeDiffs() {
 			agentList := mapKeys(agents)
 			m.diffs = append(m.diffs, DiffItem{
 				Path:       path,
-				Label:      pathToLabel(path),
+				Label:      labels[path],
 				Type:       DiffNew,
 				AgentName:  strings.Join(agentList, ", "),
 				AgentCount: len(agentList),
@@ -270,7 +287,7 @@ func (m *CompareModel) computeDiffs() {
 			agentList := mapKeys(agents)
 			m.diffs = append(m.diffs, DiffItem{
 				Path:       path,
-				Label:      pathToLabel(path),
+				Label:      labels[path],
 				Type:       DiffRemoved,
 				AgentName:  strings.Join(agentList, ", "),
 				AgentCount: len(agentList),
@@ -288,58 +305,14 @@ func (m *CompareModel) computeDiffs() {
 	})
 }
 
-// extractPaths extracts all paths from JSON data
-func extractPaths(jsonStr string) []string {
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+// extractPaths returns the path of every item in an answer, as the results
+// tree and aggregated view identify them
+func extractPaths(answer string) []resultset.Path {
+	data, err := resultset.Parse(answer)
+	if err != nil {
 		return nil
 	}
-
-	var paths []string
-	extractPathsRecursive(data, "", &paths)
-	return paths
-}
-
-func extractPathsRecursive(data interface{}, prefix string, paths *[]string) {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		for key, value := range v {
-			newPath := prefix + "/" + key
-			*paths = append(*paths, newPath)
-
-			switch child := value.(type) {
-			case map[string]interface{}, []interface{}:
-				extractPathsRecursive(child, newPath, paths)
-			default:
-				// Include leaf values as paths
-				leafPath := fmt.Sprintf("%s=%v", newPath, value)
-				*paths = append(*paths, leafPath)
-			}
-		}
-
-	case []interface{}:
-		for i, item := range v {
-			newPath := fmt.Sprintf("%s[%d]", prefix, i)
-
-			// Try to get a meaningful label from the item
-			if m, ok := item.(map[string]interface{}); ok {
-				if label := findLabel(m); label != "" {
-					newPath = prefix + "/" + label
-				}
-			}
-
-			*paths = append(*paths, newPath)
-			extractPathsRecursive(item, newPath, paths)
-		}
-	}
-}
-
-func pathToLabel(path string) string {
-	parts := strings.Split(path, "/")
-	if len(parts) > 0 {
-		return parts[len(parts)-1]
-	}
-	return path
+	return resultset.Paths(resultset.Tree(data))
 }
 
 func mapKeys(m map[string]bool) []string {
@@ -396,11 +369,15 @@ func (m *CompareModel) View() string {
 	}
 	title := fmt.Sprintf(" Compare: %s vs %s ", execID, baseID)
 	b.WriteString(ui.TitleStyle.Render(title))
+	// Cached or offline data is marked on the title line
+	if banner := sourceBanner(m.client, m.baselineID, m.executionID); banner != "" {
+		b.WriteString(" " + banner)
+	}
 	b.WriteString("\n")
 
 	// Summary
 	if m.loading {
-		b.WriteString(m.spinner.View() + " Loading results...")
+		b.WriteString(m.spinner.View() + " Loading results..." + m.loadingProgress())
 		b.WriteString("\n")
 	} else {
 		summary := fmt.Sprintf("Changes: %s new | %s removed | %s modified",
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+)
+
+// fetchProgressMsg reports page progress of a results fetch
+type fetchProgressMsg struct {
+	executionID string
+	progress    api.FetchProgress
+	ch          <-chan api.FetchProgress
+}
+
+// fetchWithProgress runs fetch with a progress option and relays progress
+// updates as fetchProgressMsg until fetch returns
+func fetchWithProgress(executionID string, fetch func(progress api.FetchOption) tea.Msg) tea.Cmd {
+	ch := make(chan api.FetchProgress, 1)
+
+	progress := api.WithProgress(func(p api.FetchProgress) {
+		// Only the latest update matters; replace any unread one
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- p:
+		default:
+		}
+	})
+
+	run := func() tea.Msg {
+		defer close(ch)
+		return fetch(progress)
+	}
+
+	return tea.Batch(run, waitForFetchProgress(executionID, ch))
+}
+
+// waitForFetchProgress waits for the next progress update, returning nil once the fetch is done
+func waitForFetchProgress(executionID string, ch <-chan api.FetchProgress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return fetchProgressMsg{executionID: executionID, progress: p, ch: ch}
+	}
+}
+
+// formatFetchProgress renders progress as "page 12/40", or "" for single-page fetches
+func formatFetchProgress(p api.FetchProgress) string {
+	if p.Pages <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("page %d/%d", p.Page, p.Pages)
+}
@@ -0,0 +1,666 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/ui"
+	"github.com/Binmave/binmave-cli/internal/ui/components"
+)
+
+// followUpStep is a step of launching a follow-up execution
+type followUpStep int
+
+const (
+	followUpScript  followUpStep = iota // Pick the script
+	followUpInputs                      // Fill in the inputs
+	followUpConfirm                     // Review the targets and run
+	followUpStarted                     // List the executions started
+)
+
+// followUp is the state of launching a script on the marked agents
+type followUp struct {
+	step       followUpStep
+	scripts    []api.Script // nil while loading
+	filter     string       // Typed text narrowing the script list
+	scriptIdx  int
+	script     api.Script
+	inputs     []api.ScriptInput
+	inputIdx   int    // len(inputs) is the row that adds an input
+	newInput   string // "key=value" typed on the add row
+	agents     []panelAgent
+	machines   []string               // Distinct machine names of the agents, one execution each
+	marked     []int                  // Marked agents per machine name
+	requests   []api.ExecuteRequest   // Built on confirmation, by machine; resubmissions reuse their idempotency keys
+	started    []*api.ExecuteResponse // Executions started so far; a resubmission continues after them
+	startedIdx int                    // Selected execution in the started list
+	submitting bool
+	err        error
+}
+
+// scriptsMsg carries the scripts to pick a follow-up from
+type scriptsMsg struct {
+	scripts []api.Script
+	err     error
+}
+
+// followUpStartedMsg reports the submission of follow-up executions: those
+// started before any failure
+type followUpStartedMsg struct {
+	responses []*api.ExecuteResponse
+	err       error
+}
+
+// openExecutionMsg asks to show another execution in place of this one
+type openExecutionMsg struct {
+	executionID string
+}
+
+// SetExecuteDenied records why the current role cannot execute scripts, so
+// follow-up executions explain it instead of failing on the server
+func (m *ResultsModel) SetExecuteDenied(err error) {
+	m.executeDenied = err
+}
+
+// toggleMarks marks the agents for a follow-up execution, or unmarks them
+// when all of them are marked already
+func (m *ResultsModel) toggleMarks(agents []panelAgent) {
+	if len(agents) == 0 {
+		return
+	}
+	all := true
+	for _, agent := range agents {
+		if _, ok := m.markedAgents[agent.ID]; !ok {
+			all = false
+			break
+		}
+	}
+	for _, agent := range agents {
+		if all {
+			delete(m.markedAgents, agent.ID)
+		} else {
+			m.markedAgents[agent.ID] = agent.Name
+		}
+	}
+	m.treeView.SetMarked(m.markedIDs())
+}
+
+// markSelection toggles the marks of the agents behind the selection: the
+// agent of a table row or tree, or every agent of an aggregated node
+func (m *ResultsModel) markSelection() {
+	var agents []panelAgent
+	switch m.viewMode {
+	case TableView:
+		if m.tableSelectedIdx < len(m.filteredTableRows) {
+			row := m.filteredTableRows[m.tableSelectedIdx]
+			agents = append(agents, panelAgent{ID: row.AgentID, Name: row.AgentName})
+		}
+	case TreeView:
+		if agent := m.treeView.GetSelectedAgent(); agent != nil {
+			agents = append(agents, panelAgent{ID: agent.AgentID, Name: agent.AgentName})
+		}
+	case AggregatedView:
+		if node := m.treeView.GetSelectedNode(); node != nil {
+			for i, id := range node.AgentIDs {
+				agents = append(agents, panelAgent{ID: id, Name: node.AgentNames[i]})
+			}
+		}
+	}
+	m.toggleMarks(agents)
+}
+
+// clearMarks unmarks every agent
+func (m *ResultsModel) clearMarks() {
+	clear(m.markedAgents)
+	m.treeView.SetMarked(nil)
+}
+
+func (m *ResultsModel) markedIDs() map[string]bool {
+	ids := make(map[string]bool, len(m.markedAgents))
+	for id := range m.markedAgents {
+		ids[id] = true
+	}
+	return ids
+}
+
+// markedList returns the marked agents by name
+func (m *ResultsModel) markedList() []panelAgent {
+	agents := make([]panelAgent, 0, len(m.markedAgents))
+	for id, name := range m.markedAgents {
+		agents = append(agents, panelAgent{ID: id, Name: name})
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if a, b := strings.ToLower(agents[i].Name), strings.ToLower(agents[j].Name); a != b {
+			return a < b
+		}
+		return agents[i].ID < agents[j].ID
+	})
+	return agents
+}
+
+// openFollowUp starts launching a script on the marked agents by loading the
+// scripts to pick from
+func (m *ResultsModel) openFollowUp() tea.Cmd {
+	m.followUp = &followUp{agents: m.markedList()}
+	m.helpBar.SetItems(components.FollowUpHelpItems())
+
+	switch {
+	case m.executeDenied != nil:
+		m.followUp.err = m.executeDenied
+	case len(m.markedAgents) == 0:
+		m.followUp.err = fmt.Errorf("no agents are marked: mark them with x first")
+	case m.client == nil:
+		m.followUp.err = fmt.Errorf("no server to run scripts on")
+	default:
+		return m.fetchScripts
+	}
+	return nil
+}
+
+func (m *ResultsModel) closeFollowUp() {
+	m.followUp = nil
+	m.updateHelpItems()
+}
+
+// fetchScripts fetches the scripts for the follow-up script picker
+func (m *ResultsModel) fetchScripts() tea.Msg {
+	scripts, err := m.client.ListScripts(m.ctx)
+	return scriptsMsg{scripts: scripts, err: err}
+}
+
+// handleScripts shows the loaded scripts with the script of this execution
+// selected
+func (m *ResultsModel) handleScripts(msg scriptsMsg) {
+	f := m.followUp
+	if f == nil {
+		return
+	}
+	if msg.err != nil {
+		f.err = fmt.Errorf("failed to list scripts: %w", msg.err)
+		return
+	}
+	f.scripts = msg.scripts
+	if f.scripts == nil {
+		f.scripts = []api.Script{}
+	}
+	if m.execution != nil {
+		for i, script := range f.scripts {
+			if script.ScriptID == m.execution.ScriptID {
+				f.scriptIdx = i
+			}
+		}
+	}
+}
+
+// filteredScripts returns the scripts whose name or ID contains the filter
+func (f *followUp) filteredScripts() []api.Script {
+	if f.filter == "" {
+		return f.scripts
+	}
+	filter := strings.ToLower(f.filter)
+	var scripts []api.Script
+	for _, script := range f.scripts {
+		if strings.Contains(strings.ToLower(script.Name), filter) || strings.Contains(strconv.Itoa(script.ScriptID), filter) {
+			scripts = append(scripts, script)
+		}
+	}
+	return scripts
+}
+
+// updateFollowUp handles keys while a follow-up execution is being launched
+func (m *ResultsModel) updateFollowUp(msg tea.KeyMsg) tea.Cmd {
+	f := m.followUp
+	key := msg.String()
+	if key == "ctrl+c" {
+		return tea.Quit
+	}
+	if f.submitting {
+		return nil
+	}
+	if f.err != nil && f.step == followUpScript {
+		// Errors before a script is picked end the follow-up
+		if key == "esc" || key == "enter" || key == "q" {
+			m.closeFollowUp()
+		}
+		return nil
+	}
+
+	switch f.step {
+	case followUpScript:
+		m.updateScriptPicker(key)
+	case followUpInputs:
+		m.updateInputs(key)
+	case followUpConfirm:
+		switch key {
+		case "esc":
+			f.err = nil
+			f.step = followUpInputs
+			if len(f.started) > 0 {
+				// Going back would run the script again where it started
+				f.step = followUpStarted
+			}
+		case "enter", "y":
+			f.err = nil
+			f.submitting = true
+			return m.submitFollowUp(f.script.ScriptID, f.requests[len(f.started):])
+		}
+	case followUpStarted:
+		switch key {
+		case "esc", "q":
+			m.closeFollowUp()
+		case "up":
+			if f.startedIdx > 0 {
+				f.startedIdx--
+			}
+		case "down":
+			if f.startedIdx < len(f.started)-1 {
+				f.startedIdx++
+			}
+		case "enter":
+			id := f.started[f.startedIdx].ExecutionID
+			return func() tea.Msg { return openExecutionMsg{executionID: id} }
+		}
+	}
+	return nil
+}
+
+// updateScriptPicker handles keys in the script picker; typing filters it
+func (m *ResultsModel) updateScriptPicker(key string) {
+	f := m.followUp
+	if f.scripts == nil {
+		if key == "esc" {
+			m.closeFollowUp()
+		}
+		return
+	}
+	scripts := f.filteredScripts()
+
+	switch key {
+	case "esc":
+		m.closeFollowUp()
+	case "up":
+		if f.scriptIdx > 0 {
+			f.scriptIdx--
+		}
+	case "down":
+		if f.scriptIdx < len(scripts)-1 {
+			f.scriptIdx++
+		}
+	case "enter":
+		if f.scriptIdx >= len(scripts) {
+			return
+		}
+		f.script = scripts[f.scriptIdx]
+		f.inputs = nil
+		// Inputs of this execution are a starting point for the same script
+		if m.execution != nil && m.execution.ScriptID == f.script.ScriptID {
+			f.inputs = append(f.inputs, m.execution.Inputs...)
+		}
+		f.inputIdx = 0
+		f.newInput = ""
+		f.step = followUpInputs
+	case "backspace":
+		if len(f.filter) > 0 {
+			f.filter = f.filter[:len(f.filter)-1]
+			f.scriptIdx = 0
+		}
+	default:
+		if isPrintable(key) {
+			f.filter += key
+			f.scriptIdx = 0
+		}
+	}
+}
+
+// updateInputs handles keys while filling in inputs: typing edits the value
+// of the selected input, or a "key=value" on the add row
+func (m *ResultsModel) updateInputs(key string) {
+	f := m.followUp
+	adding := f.inputIdx == len(f.inputs)
+	f.err = nil
+
+	switch key {
+	case "esc":
+		f.step = followUpScript
+	case "up":
+		if f.inputIdx > 0 {
+			f.inputIdx--
+		}
+	case "down":
+		if !adding {
+			f.inputIdx++
+		}
+	case "enter":
+		if adding && f.newInput != "" {
+			name, value, ok := strings.Cut(f.newInput, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				f.err = fmt.Errorf("type an input as key=value")
+				return
+			}
+			f.inputs = append(f.inputs, api.ScriptInput{Key: strings.TrimSpace(name), Value: value, Type: "string"})
+			f.inputIdx = len(f.inputs)
+			f.newInput = ""
+			return
+		}
+		for i, input := range f.inputs {
+			if input.Required && input.Value == "" {
+				f.inputIdx = i
+				f.err = fmt.Errorf("input %s is required", input.Key)
+				return
+			}
+		}
+		m.confirmFollowUp()
+	case "backspace":
+		if adding {
+			if len(f.newInput) > 0 {
+				f.newInput = f.newInput[:len(f.newInput)-1]
+			}
+		} else if value := f.inputs[f.inputIdx].Value; len(value) > 0 {
+			f.inputs[f.inputIdx].Value = value[:len(value)-1]
+		}
+	case "ctrl+d":
+		// Remove the selected input
+		if !adding {
+			f.inputs = append(f.inputs[:f.inputIdx], f.inputs[f.inputIdx+1:]...)
+		}
+	default:
+		if !isPrintable(key) {
+			return
+		}
+		if adding {
+			f.newInput += key
+		} else {
+			f.inputs[f.inputIdx].Value += key
+		}
+	}
+}
+
+// confirmFollowUp builds the requests to confirm: one per machine name, as
+// the documented machineName filter is the only way to target agents. Names
+// the filter cannot express exactly keep the follow-up at the inputs step.
+// The idempotency keys are made here, so that a resubmission carries the same
+// key as the first attempt.
+func (m *ResultsModel) confirmFollowUp() {
+	f := m.followUp
+	var machines []string
+	var marked []int
+	var requests []api.ExecuteRequest
+	index := make(map[string]int, len(f.agents))
+	for _, agent := range f.agents {
+		if i, ok := index[agent.Name]; ok {
+			marked[i]++
+			continue
+		}
+		filter, err := api.MachineNameFilter(agent.Name)
+		if err != nil {
+			f.err = err
+			return
+		}
+		index[agent.Name] = len(machines)
+		machines = append(machines, agent.Name)
+		marked = append(marked, 1)
+		requests = append(requests, api.ExecuteRequest{
+			FilterGridString: filter,
+			Inputs:           f.inputs,
+			CleanSandBox:     false,
+			IdempotencyKey:   api.NewIdempotencyKey(),
+		})
+	}
+	f.machines, f.marked, f.requests = machines, marked, requests
+	f.step = followUpConfirm
+}
+
+// mismatches describes the started executions that expect a different number
+// of agents than were marked under their machine name
+func (f *followUp) mismatches() []string {
+	var mismatches []string
+	for i, resp := range f.started {
+		if resp.ExpectedAgents != f.marked[i] {
+			mismatches = append(mismatches, fmt.Sprintf("%s expects %d agents, %d marked", f.machines[i], resp.ExpectedAgents, f.marked[i]))
+		}
+	}
+	return mismatches
+}
+
+// submitFollowUp runs the script with each request in turn, stopping at the
+// first failure
+func (m *ResultsModel) submitFollowUp(scriptID int, reqs []api.ExecuteRequest) tea.Cmd {
+	client, ctx := m.client, m.ctx
+	return func() tea.Msg {
+		var started []*api.ExecuteResponse
+		for _, req := range reqs {
+			resp, err := client.ExecuteScript(ctx, scriptID, req)
+			if err != nil {
+				return followUpStartedMsg{responses: started, err: err}
+			}
+			started = append(started, resp)
+		}
+		return followUpStartedMsg{responses: started}
+	}
+}
+
+// handleFollowUpStarted records the executions started, opening the new
+// execution when there is just one and it targets the marked agents
+func (m *ResultsModel) handleFollowUpStarted(msg followUpStartedMsg) (tea.Model, tea.Cmd) {
+	f := m.followUp
+	if f == nil {
+		return m, nil
+	}
+	f.submitting = false
+	f.started = append(f.started, msg.responses...)
+	if msg.err != nil {
+		f.err = fmt.Errorf("failed to execute script on %s: %w", f.machines[len(f.started)], msg.err)
+		return m, nil
+	}
+	if len(f.started) > 1 || len(f.mismatches()) > 0 {
+		f.step = followUpStarted
+		return m, nil
+	}
+	// Show the new execution, which fills in as agents report
+	return m.switchExecution(f.started[0].ExecutionID)
+}
+
+// switchExecution replaces the model with one showing another execution,
+// keeping the window, search history and settings that suit any script
+func (m *ResultsModel) switchExecution(executionID string) (tea.Model, tea.Cmd) {
+	next := NewResultsModel(executionID, m.client)
+	next.width = m.width
+	next.height = m.height
+	next.ready = m.ready
+	next.searchHistory = m.searchHistory
+	next.historyIdx = len(m.searchHistory)
+	next.anomalyThreshold = m.anomalyThreshold
+	next.executeDenied = m.executeDenied
+	next.fleet = m.fleet
+	next.updateDimensions()
+	return next, next.Init()
+}
+
+// renderFollowUp renders the current step of launching a follow-up execution
+func (m *ResultsModel) renderFollowUp(height int) string {
+	f := m.followUp
+	var b strings.Builder
+	b.WriteString(ui.HeaderStyle.Render("Run a script on marked agents"))
+	b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  (%d agents)", len(f.agents))))
+	b.WriteString("\n\n")
+
+	switch {
+	case f.err != nil && f.step == followUpScript:
+		b.WriteString(ui.ErrorStyle.Render("✗ " + f.err.Error()))
+		b.WriteString("\n")
+		b.WriteString(ui.MutedStyle.Render("Press Esc to go back"))
+	case f.step == followUpScript:
+		m.renderScriptPicker(&b, height-3)
+	case f.step == followUpInputs:
+		m.renderInputs(&b)
+	case f.step == followUpStarted:
+		m.renderStarted(&b, height-3)
+	default:
+		m.renderConfirm(&b, height-3)
+	}
+	return b.String()
+}
+
+func (m *ResultsModel) renderScriptPicker(b *strings.Builder, height int) {
+	f := m.followUp
+	if f.scripts == nil {
+		b.WriteString(m.spinner.View() + " Loading scripts...")
+		return
+	}
+
+	b.WriteString(ui.MutedStyle.Render("Script: "))
+	b.WriteString(ui.SelectedStyle.Render(f.filter + "█"))
+	b.WriteString("\n")
+
+	scripts := f.filteredScripts()
+	if len(scripts) == 0 {
+		b.WriteString(ui.MutedStyle.Render("  No matching scripts"))
+		return
+	}
+
+	// Scroll to keep the selection visible
+	lines := max(height-1, 1)
+	start := 0
+	if f.scriptIdx >= lines {
+		start = f.scriptIdx - lines + 1
+	}
+	end := min(start+lines, len(scripts))
+	for i := start; i < end; i++ {
+		script := scripts[i]
+		line := fmt.Sprintf("%6d  %s", script.ScriptID, truncateString(script.Name, max(m.width-10, 20)))
+		if i == f.scriptIdx {
+			b.WriteString(ui.SelectedStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func (m *ResultsModel) renderInputs(b *strings.Builder) {
+	f := m.followUp
+	b.WriteString(ui.MutedStyle.Render("Script: "))
+	b.WriteString(fmt.Sprintf("%s (ID: %d)", f.script.Name, f.script.ScriptID))
+	b.WriteString("\n\n")
+
+	for i, input := range f.inputs {
+		name := input.Key
+		if input.Required {
+			name += "*"
+		}
+		if i == f.inputIdx {
+			b.WriteString(ui.SelectedStyle.Render(fmt.Sprintf("%s = %s█", name, input.Value)))
+		} else {
+			b.WriteString(fmt.Sprintf("%s = %s", name, input.Value))
+		}
+		b.WriteString("\n")
+	}
+	if f.inputIdx == len(f.inputs) {
+		b.WriteString(ui.SelectedStyle.Render("+ " + f.newInput + "█"))
+	} else {
+		b.WriteString(ui.MutedStyle.Render("+ add input"))
+	}
+	b.WriteString("\n\n")
+
+	if f.err != nil {
+		b.WriteString(ui.ErrorStyle.Render("✗ " + f.err.Error()))
+	} else {
+		b.WriteString(ui.MutedStyle.Render("Type to edit the selected value, key=value on + to add an input, Ctrl+D to remove one; Enter to continue"))
+	}
+}
+
+func (m *ResultsModel) renderConfirm(b *strings.Builder, height int) {
+	f := m.followUp
+	b.WriteString(fmt.Sprintf("Run %s (ID: %d) on %d agents", f.script.Name, f.script.ScriptID, len(f.agents)))
+	b.WriteString("\n")
+	if len(f.machines) > 1 {
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  One execution per machine name: %d executions", len(f.machines))))
+		b.WriteString("\n")
+	}
+	b.WriteString(ui.MutedStyle.Render("  Targeted with machineName filters: agents sharing a marked name run it too"))
+	b.WriteString("\n")
+	for _, input := range f.inputs {
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  %s = %s", input.Key, input.Value)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// List the targets, leaving room for the inputs and the prompt
+	lines := max(height-len(f.inputs)-6, 1)
+	for i, agent := range f.agents {
+		if i == lines-1 && len(f.agents) > lines {
+			b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  ... and %d more", len(f.agents)-i)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString("  " + ui.MarkedAgent + " " + agent.Name)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case f.submitting:
+		b.WriteString(m.spinner.View() + " Starting execution...")
+	case f.err != nil:
+		b.WriteString(ui.ErrorStyle.Render("✗ " + f.err.Error()))
+		b.WriteString("\n")
+		if len(f.started) > 0 {
+			b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("Started %d of %d executions; submitting again runs the rest", len(f.started), len(f.requests))))
+			b.WriteString("\n")
+		}
+		if api.IsAmbiguous(f.err) {
+			// The request may have reached the server before the failure
+			b.WriteString(ui.WarningStyle.Render("The execution may have started anyway; check 'binmave executions' before submitting again"))
+			b.WriteString("\n")
+		}
+		b.WriteString(ui.MutedStyle.Render("Enter to submit again, Esc to go back"))
+	default:
+		b.WriteString(ui.WarningStyle.Render("Enter to run, Esc to go back"))
+	}
+}
+
+// renderStarted lists the executions started by machine name, warning about
+// those whose filter matched a different number of agents than were marked
+func (m *ResultsModel) renderStarted(b *strings.Builder, height int) {
+	f := m.followUp
+	b.WriteString(fmt.Sprintf("Started %s (ID: %d) in %d executions", f.script.Name, f.script.ScriptID, len(f.started)))
+	b.WriteString("\n\n")
+
+	mismatches := f.mismatches()
+	for _, mismatch := range mismatches {
+		b.WriteString(ui.WarningStyle.Render("⚠ " + mismatch))
+		b.WriteString("\n")
+	}
+	if len(mismatches) > 0 {
+		b.WriteString("\n")
+	}
+
+	// Scroll to keep the selection visible
+	lines := max(height-4-len(mismatches), 1)
+	start := 0
+	if f.startedIdx >= lines {
+		start = f.startedIdx - lines + 1
+	}
+	end := min(start+lines, len(f.started))
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("  %s  %s (%d agents)", f.started[i].ExecutionID, f.machines[i], f.started[i].ExpectedAgents)
+		if i == f.startedIdx {
+			b.WriteString(ui.SelectedStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(ui.MutedStyle.Render("Enter to open the selected execution, Esc to go back to these results"))
+}
+
+// isPrintable reports whether a key is a single printable ASCII character
+func isPrintable(key string) bool {
+	return len(key) == 1 && key[0] >= 32 && key[0] < 127
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/api/apitest"
+	"github.com/Binmave/binmave-cli/internal/api/fake"
+)
+
+// startFollowUp marks agents (name by ID), confirms script 42 on them and
+// submits it, returning the model the TUI shows afterwards
+func startFollowUp(t *testing.T, backend *fake.Backend, marked map[string]string) (*ResultsModel, tea.Model) {
+	t.Helper()
+	m := NewResultsModel(apitest.ProcessesExecutionID, backend)
+	m.markedAgents = marked
+	m.openFollowUp()
+	f := m.followUp
+	f.script = api.Script{ScriptID: 42}
+	f.inputs = []api.ScriptInput{{Key: "path", Value: `C:\Temp`}}
+	m.confirmFollowUp()
+	if f.err != nil {
+		t.Fatalf("confirmFollowUp: %v", f.err)
+	}
+
+	msg := m.submitFollowUp(f.script.ScriptID, f.requests)().(followUpStartedMsg)
+	if msg.err != nil {
+		t.Fatalf("submitFollowUp: %v", msg.err)
+	}
+	next, _ := m.Update(msg)
+	return m, next
+}
+
+func TestFollowUpOpensSingleExecution(t *testing.T) {
+	backend := apitest.MustFixtures()
+	_, next := startFollowUp(t, backend, map[string]string{
+		"a1f0c6d2-3b4e-4f51-9a7c-1d2e3f405161": "WS-FIN-001",
+	})
+
+	if len(backend.Submissions) != 1 {
+		t.Fatalf("got %d submissions, want 1", len(backend.Submissions))
+	}
+	s := backend.Submissions[0]
+	if s.Request.FilterGridString != "machineName=WS-FIN-001" {
+		t.Errorf("filter = %q", s.Request.FilterGridString)
+	}
+	if s.ScriptID != 42 || len(s.Request.Inputs) != 1 || s.Request.Inputs[0].Value != `C:\Temp` {
+		t.Errorf("submission = script %d with inputs %+v", s.ScriptID, s.Request.Inputs)
+	}
+	if rm, ok := next.(*ResultsModel); !ok || rm.executionID != s.Response.ExecutionID {
+		t.Errorf("TUI did not switch to the new execution %s", s.Response.ExecutionID)
+	}
+}
+
+func TestFollowUpListsExecutionsPerMachineName(t *testing.T) {
+	backend := apitest.MustFixtures()
+	m, next := startFollowUp(t, backend, map[string]string{
+		"a1f0c6d2-3b4e-4f51-9a7c-1d2e3f405161": "WS-FIN-001",
+		"c3f2e8f4-5d60-4173-9c9e-3f4051627383": "SRV-DC-01",
+	})
+	if next != m {
+		t.Fatal("TUI switched away with several executions started")
+	}
+
+	// One execution per machine name, in name order
+	want := []string{"machineName=SRV-DC-01", "machineName=WS-FIN-001"}
+	if len(backend.Submissions) != len(want) {
+		t.Fatalf("got %d submissions, want %d", len(backend.Submissions), len(want))
+	}
+	for i, s := range backend.Submissions {
+		if s.Request.FilterGridString != want[i] {
+			t.Errorf("submission %d filter = %q, want %q", i, s.Request.FilterGridString, want[i])
+		}
+	}
+
+	// Enter opens the selected execution, not just the first
+	f := m.followUp
+	if f.step != followUpStarted || len(f.mismatches()) != 0 {
+		t.Fatalf("step = %d, mismatches %v", f.step, f.mismatches())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(openExecutionMsg); !ok || msg.executionID != backend.Submissions[1].Response.ExecutionID {
+		t.Errorf("Enter opened %+v, want %s", msg, backend.Submissions[1].Response.ExecutionID)
+	}
+}
+
+func TestFollowUpWarnsWhenFilterMatchesOtherAgents(t *testing.T) {
+	backend := apitest.MustFixtures()
+	// A second agent shares the marked agent's name
+	extra := backend.Agents[0]
+	extra.AgentID = "unmarked"
+	backend.Agents = append(backend.Agents, extra)
+
+	m, next := startFollowUp(t, backend, map[string]string{
+		backend.Agents[0].AgentID: backend.Agents[0].MachineName,
+	})
+	if next != m {
+		t.Fatal("TUI switched away from an execution that targets unmarked agents")
+	}
+	mismatches := m.followUp.mismatches()
+	if len(mismatches) != 1 || !strings.Contains(mismatches[0], "expects 2 agents, 1 marked") {
+		t.Errorf("mismatches = %v", mismatches)
+	}
+}
+
+func TestFollowUpRejectsNamesTheFilterCannotExpress(t *testing.T) {
+	backend := apitest.MustFixtures()
+	m := NewResultsModel(apitest.ProcessesExecutionID, backend)
+	m.markedAgents = map[string]string{"agent-1": "web 01,db-01"}
+	m.openFollowUp()
+	m.followUp.step = followUpInputs
+	m.confirmFollowUp()
+
+	if m.followUp.err == nil || m.followUp.step != followUpInputs {
+		t.Errorf("confirmed a follow-up on %q: step %d, err %v", "web 01,db-01", m.followUp.step, m.followUp.err)
+	}
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Binmave/binmave-cli/internal/resultset"
+	"github.com/Binmave/binmave-cli/internal/ui"
+)
+
+// SetGroupColumns sets the columns the group view groups rows by
+func (m *ResultsModel) SetGroupColumns(columns []string) {
+	m.groupColumns = columns
+}
+
+// rebuildGroups groups the rows matching the search, keeping the selected
+// group selected when it still exists
+func (m *ResultsModel) rebuildGroups() {
+	if len(m.groupColumns) == 0 {
+		// Start with the column focused in the table view
+		columns := m.visibleColumns()
+		if len(columns) == 0 {
+			m.groups = nil
+			return
+		}
+		m.groupColumns = []string{columns[m.focusedColumnIndex()]}
+	}
+
+	var selected string
+	if m.groupSelectedIdx < len(m.groups) {
+		selected = m.groups[m.groupSelectedIdx].ID
+	}
+
+	grouper := resultset.NewGrouper(m.groupColumns)
+	for _, row := range m.tableRows {
+		if m.searchFilter.Match(row.AgentName, row.Data) {
+			grouper.Add(row.AgentID, row.Data)
+		}
+	}
+	m.groups = grouper.Groups()
+
+	m.groupSelectedIdx = min(m.groupSelectedIdx, max(len(m.groups)-1, 0))
+	for i, group := range m.groups {
+		if group.ID == selected {
+			m.groupSelectedIdx = i
+			break
+		}
+	}
+	m.ensureGroupVisible()
+}
+
+// ensureGroupVisible scrolls to keep the selected group visible
+func (m *ResultsModel) ensureGroupVisible() {
+	viewportHeight := m.height - 11 // Header, tabs and the group summary line
+	if m.groupSelectedIdx < m.groupScrollOffset {
+		m.groupScrollOffset = m.groupSelectedIdx
+	}
+	if m.groupSelectedIdx >= m.groupScrollOffset+viewportHeight {
+		m.groupScrollOffset = m.groupSelectedIdx - viewportHeight + 1
+	}
+}
+
+// enterGroup shows the rows of the selected group in the table view
+func (m *ResultsModel) enterGroup() {
+	if m.groupSelectedIdx >= len(m.groups) {
+		return
+	}
+	m.drillGroup = m.groups[m.groupSelectedIdx]
+	m.tableSelectedIdx = 0
+	m.tableScrollOffset = 0
+	m.setViewMode(TableView)
+	m.applySearchFilter()
+}
+
+// leaveGroup goes back from a group's rows to the group view
+func (m *ResultsModel) leaveGroup() {
+	m.drillGroup = nil
+	m.tableSelectedIdx = 0
+	m.tableScrollOffset = 0
+	m.setViewMode(GroupView)
+	m.applySearchFilter()
+}
+
+// groupLabel describes a group by its column values
+func (m *ResultsModel) groupLabel(group *resultset.Group) string {
+	parts := make([]string, len(m.groupColumns))
+	for i, col := range m.groupColumns {
+		parts[i] = col + "=" + groupValue(group.Key[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// groupValue formats a group-by value, naming rows without the column
+func groupValue(value resultset.Value) string {
+	if value.Kind == resultset.KindNull && !value.IsNull() {
+		return "(missing)"
+	}
+	return formatCell(value)
+}
+
+// groupColumn is a column of the group view
+type groupColumn struct {
+	header string
+	right  bool // Right-aligned
+	cell   func(g *resultset.Group) string
+}
+
+// groupViewColumns returns the group-by columns, the counts, and min, max
+// and sum of every visible numeric column
+func (m *ResultsModel) groupViewColumns() []groupColumn {
+	var columns []groupColumn
+	for i, col := range m.groupColumns {
+		columns = append(columns, groupColumn{
+			header: strings.ToUpper(col),
+			right:  m.columnKinds[col] == resultset.KindNumber,
+			cell:   func(g *resultset.Group) string { return groupValue(g.Key[i]) },
+		})
+	}
+	columns = append(columns,
+		groupColumn{header: "ROWS", right: true, cell: func(g *resultset.Group) string { return strconv.Itoa(g.Rows) }},
+		groupColumn{header: "AGENTS", right: true, cell: func(g *resultset.Group) string { return strconv.Itoa(g.Agents()) }},
+	)
+
+	for _, col := range m.visibleColumns() {
+		if m.columnKinds[col] != resultset.KindNumber || slices.Contains(m.groupColumns, col) {
+			continue
+		}
+		for _, stat := range []string{"MIN", "MAX", "SUM"} {
+			columns = append(columns, groupColumn{
+				header: strings.ToUpper(col) + " " + stat,
+				right:  true,
+				cell: func(g *resultset.Group) string {
+					stats := g.Stats[col]
+					if stats == nil {
+						return ""
+					}
+					switch stat {
+					case "MIN":
+						return formatNumber(stats.Min)
+					case "MAX":
+						return formatNumber(stats.Max)
+					}
+					return formatNumber(stats.Sum)
+				},
+			})
+		}
+	}
+	return columns
+}
+
+// renderGroupView renders one line per group. Columns that do not fit the
+// terminal are left out and counted at the end of the divider.
+func (m *ResultsModel) renderGroupView(height int) string {
+	if len(m.groups) == 0 {
+		if m.searchQuery != "" {
+			return ui.MutedStyle.Render("No results matching \"" + m.searchQuery + "\"")
+		}
+		return ui.MutedStyle.Render("No results to group")
+	}
+
+	var b strings.Builder
+	rows := 0
+	for _, group := range m.groups {
+		rows += group.Rows
+	}
+	b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("Grouped by %s: %d groups of %d rows (g to change, Enter for rows)",
+		strings.Join(m.groupColumns, ", "), len(m.groups), rows)))
+	b.WriteString("\n")
+
+	// Widths from the headers and the first groups
+	columns := m.groupViewColumns()
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = utf8.RuneCountInString(col.header)
+		for _, group := range m.groups[:min(len(m.groups), 500)] {
+			widths[i] = max(widths[i], utf8.RuneCountInString(col.cell(group)))
+		}
+		widths[i] = min(widths[i], 40)
+	}
+
+	// Group-by columns are always shown; the rest while they fit
+	shown := len(columns)
+	used := 0
+	for i := range columns {
+		if used+widths[i]+3 > m.width-2 && i >= len(m.groupColumns) {
+			shown = i
+			break
+		}
+		used += widths[i] + 3
+	}
+
+	line := func(cell func(i int, col groupColumn) string) string {
+		parts := make([]string, shown)
+		for i, col := range columns[:shown] {
+			text := truncateString(cell(i, col), widths[i])
+			if col.right {
+				parts[i] = fmt.Sprintf("%*s", widths[i], text)
+			} else {
+				parts[i] = fmt.Sprintf("%-*s", widths[i], text)
+			}
+		}
+		return strings.Join(parts, " │ ")
+	}
+
+	header := line(func(i int, col groupColumn) string { return col.header })
+	b.WriteString(ui.HeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	divider := min(utf8.RuneCountInString(header)+10, m.width-2)
+	hint := ""
+	if shown < len(columns) {
+		hint = fmt.Sprintf(" %d more columns", len(columns)-shown)
+		divider = max(divider-utf8.RuneCountInString(hint), 0)
+	}
+	b.WriteString(ui.MutedStyle.Render(strings.Repeat("─", divider) + hint))
+	b.WriteString("\n")
+
+	endIdx := min(m.groupScrollOffset+height-5, len(m.groups))
+	for i := m.groupScrollOffset; i < endIdx; i++ {
+		group := m.groups[i]
+		text := line(func(_ int, col groupColumn) string { return col.cell(group) })
+		if i == m.groupSelectedIdx {
+			text = ui.SelectedStyle.Render(text)
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// formatNumber formats a statistic: whole numbers without a fraction, others
+// to ten significant digits
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatFloat(f, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(f, 'g', 10, 64)
+}
@@ -3,10 +3,13 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -14,6 +17,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/resultset"
 	"github.com/Binmave/binmave-cli/internal/ui"
 	"github.com/Binmave/binmave-cli/internal/ui/components"
 )
@@ -25,6 +29,7 @@ const (
 	TableView ViewMode = iota
 	TreeView
 	AggregatedView
+	GroupView
 )
 
 // TabIndex represents the current tab
@@ -39,36 +44,57 @@ const (
 type TableRow struct {
 	AgentName string
 	AgentID   string
-	Data      map[string]string
+	Data      map[string]resultset.Value
 	RowIndex  int
 	HasError  bool
 }
 
+// pickerTarget is what the column picker chooses columns for
+type pickerTarget int
+
+const (
+	pickTableColumns pickerTarget = iota
+	pickGroupColumns
+	pickStackColumns
+)
+
+// pickerColumn is a column as listed in the column picker
+type pickerColumn struct {
+	Name    string
+	Visible bool
+}
+
 // ResultsModel is the main TUI model for viewing execution results
 type ResultsModel struct {
 	executionID string
-	client      *api.Client
+	client      api.Backend
 	ctx         context.Context
 
 	// Data
 	execution     *api.Execution
 	status        *api.ExecutionStatus
 	results       []api.ExecutionResult
+	resultKeys    []string // api.ResultKey of each loaded result, in order
 	errors        []api.ExecutionResult
 	agentTrees    []*components.AgentTree
+	answerTrees   map[string][]*resultset.Node // Parsed answer of each agent tree, by agent ID
 	aggregateTree []*components.TreeNode
 
 	// Table data (parsed from JSON)
 	tableRows         []TableRow
 	filteredTableRows []TableRow // Rows after search filter applied
 	tableColumns      []string
-	isTreeData        bool // True if data is hierarchical (tree/aggregated views applicable)
+	columnSet         map[string]bool
+	columnKinds       map[string]resultset.Kind // Inferred type of each column
+	isTreeData        bool                      // True if data is hierarchical (tree/aggregated views applicable)
 
 	// UI State
 	viewMode         ViewMode
 	currentTab       TabIndex
 	showAnomaliesOnly bool
 	loading          bool
+	refreshing       bool // An incremental refresh is in flight
+	fetchProgress    api.FetchProgress
 	err              error
 
 	// Components
@@ -83,6 +109,43 @@ type ResultsModel struct {
 	// Table view state
 	tableSelectedIdx  int
 	tableScrollOffset int
+	focusedColumn     string              // Column that sort keys act on
+	sortKeys          []resultset.SortKey // Table sort order, most significant first
+	columnLayout      []string            // Visible columns in order; nil shows all
+	columnsChanged    bool                // The layout was changed in the column picker
+	columnOffset      int                 // First visible column scrolled into view after AGENT
+
+	// Column picker state
+	columnPicker    []pickerColumn // Non-nil while the picker is open
+	columnPickerIdx int
+	pickerFor       pickerTarget
+
+	// Group view state
+	groupColumns      []string // Columns rows are grouped by
+	groups            []*resultset.Group
+	groupSelectedIdx  int
+	groupScrollOffset int
+	drillGroup        *resultset.Group // Group whose rows the table is limited to
+
+	// Aggregated view state
+	stackColumns     []string            // Columns whose values are stacked; nil merges tree paths
+	anomalyThreshold resultset.Threshold // Agent count at or below which a node is an anomaly
+	rarestFirst      bool                // Order nodes least common first
+
+	// Agent panel state
+	agentPanel        *components.TreeNode // Aggregated node whose agents are listed; nil when closed
+	agentPanelIdx     int
+	agentPanelScroll  int
+	agentPanelLacking bool                 // List the agents that lack the node instead
+	drillAgent        *panelAgent          // Agent whose rows the table is limited to
+	fleet             map[string]api.Agent // Agents from ListAgents by ID, loaded when the panel first opens
+	fleetErr          error
+	fleetLoading      bool
+
+	// Follow-up execution state
+	markedAgents  map[string]string // Agents marked for a follow-up execution: name by ID
+	followUp      *followUp         // Non-nil while launching a follow-up execution
+	executeDenied error             // Why the role cannot execute scripts; nil if it may
 
 	// Detail view state
 	showingDetail  bool
@@ -92,7 +155,12 @@ type ResultsModel struct {
 	// Search state
 	searchMode    bool
 	searchQuery   string
-	searchMatches int // Number of matches found
+	searchMatches int              // Number of matches found
+	searchFilter  *resultset.Query // Last valid parse of searchQuery; nil matches everything
+	searchErr     error            // Syntax error in searchQuery, shown in the search bar
+	searchHistory []string         // Applied queries, oldest first
+	historyIdx    int              // Position in searchHistory while browsing with up/down
+	searchDraft   string           // Query typed before browsing the history
 
 	// Dimensions
 	width  int
@@ -111,6 +179,12 @@ type resultsMsg struct {
 	err     error
 }
 
+// newResultsMsg carries results that arrived since the last fetch
+type newResultsMsg struct {
+	results []api.ExecutionResult
+	err     error
+}
+
 type executionMsg struct {
 	execution *api.Execution
 	err       error
@@ -133,8 +207,36 @@ func (m *ResultsModel) SetAnomaliesOnly(only bool) {
 	m.showAnomaliesOnly = only
 }
 
+// SetColumns sets the visible table columns and their order before running;
+// nil shows every column
+func (m *ResultsModel) SetColumns(columns []string) {
+	m.columnLayout = columns
+}
+
+// ColumnLayout returns the visible table columns (nil for all), and whether
+// they were changed in the column picker
+func (m *ResultsModel) ColumnLayout() ([]string, bool) {
+	return m.columnLayout, m.columnsChanged
+}
+
+// ScriptID returns the script of the execution shown, once it has loaded
+func (m *ResultsModel) ScriptID() (int, bool) {
+	if m.execution == nil {
+		return 0, false
+	}
+	return m.execution.ScriptID, true
+}
+
+// SetSort sets the table sort order before running
+func (m *ResultsModel) SetSort(keys []resultset.SortKey) {
+	m.sortKeys = keys
+	if len(keys) > 0 {
+		m.focusedColumn = keys[0].Column
+	}
+}
+
 // NewResultsModel creates a new results TUI model
-func NewResultsModel(executionID string, client *api.Client) *ResultsModel {
+func NewResultsModel(executionID string, client api.Backend) *ResultsModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(ui.Primary)
@@ -145,13 +247,16 @@ func NewResultsModel(executionID string, client *api.Client) *ResultsModel {
 	})
 
 	return &ResultsModel{
-		executionID: executionID,
-		client:      client,
-		ctx:         context.Background(),
+		executionID:  executionID,
+		client:       client,
+		ctx:          context.Background(),
+		answerTrees:  make(map[string][]*resultset.Node),
+		markedAgents: make(map[string]string),
 
-		viewMode:   TableView,
-		currentTab: ResultsTab,
-		loading:    true,
+		viewMode:         TableView,
+		currentTab:       ResultsTab,
+		loading:          true,
+		anomalyThreshold: resultset.DefaultThreshold,
 
 		tabBar:      tabBar,
 		viewModeBar: components.NewViewModeBar(),
@@ -171,7 +276,7 @@ func (m *ResultsModel) Init() tea.Cmd {
 		m.spinner.Tick,
 		m.fetchExecution,
 		m.fetchStatus,
-		m.fetchResults,
+		m.fetchResults(),
 	)
 }
 
@@ -187,10 +292,30 @@ func (m *ResultsModel) fetchStatus() tea.Msg {
 	return statusMsg{status: status, err: err}
 }
 
-// fetchResults fetches all execution results
-func (m *ResultsModel) fetchResults() tea.Msg {
-	results, err := m.client.GetAllExecutionResults(m.ctx, m.executionID)
-	return resultsMsg{results: results, err: err}
+// fetchResults fetches all execution results, reporting page progress
+func (m *ResultsModel) fetchResults() tea.Cmd {
+	return fetchWithProgress(m.executionID, func(progress api.FetchOption) tea.Msg {
+		results, err := api.GetAllExecutionResults(m.ctx, m.client, m.executionID, progress)
+		return resultsMsg{results: results, err: err}
+	})
+}
+
+// fetchNewResults fetches only results that arrived since the last fetch
+func (m *ResultsModel) fetchNewResults() tea.Cmd {
+	loaded := append([]string(nil), m.resultKeys...)
+	return func() tea.Msg {
+		results, err := api.FetchNewExecutionResults(m.ctx, m.client, m.executionID, loaded)
+		return newResultsMsg{results: results, err: err}
+	}
+}
+
+// refreshResults starts an incremental refresh unless one is already running
+func (m *ResultsModel) refreshResults() tea.Cmd {
+	if m.loading || m.refreshing {
+		return nil
+	}
+	m.refreshing = true
+	return m.fetchNewResults()
 }
 
 // tick returns a tick command for auto-refresh
@@ -224,6 +349,18 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.columnPicker != nil {
+			return m, m.updateColumnPicker(msg)
+		}
+
+		if m.agentPanel != nil {
+			return m, m.updateAgentPanel(msg)
+		}
+
+		if m.followUp != nil {
+			return m, m.updateFollowUp(msg)
+		}
+
 		// Handle search mode
 		if m.searchMode {
 			switch msg.String() {
@@ -234,12 +371,23 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.applySearchFilter()
 				return m, nil
 			case "enter":
-				// Exit search mode but keep query
+				// Exit search mode but keep query; a query with an error stays open
+				if m.searchErr != nil {
+					return m, nil
+				}
 				m.searchMode = false
+				m.addSearchHistory(m.searchQuery)
+				return m, nil
+			case "up":
+				m.browseSearchHistory(-1)
+				return m, nil
+			case "down":
+				m.browseSearchHistory(1)
 				return m, nil
 			case "backspace":
 				if len(m.searchQuery) > 0 {
 					m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
+					m.historyIdx = len(m.searchHistory)
 					m.applySearchFilter()
 				}
 				return m, nil
@@ -249,6 +397,7 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Add character to search query (filter to printable chars)
 				if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] < 127 {
 					m.searchQuery += msg.String()
+					m.historyIdx = len(m.searchHistory)
 					m.applySearchFilter()
 				}
 				return m, nil
@@ -262,9 +411,19 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "/":
 			// Enter search mode
 			m.searchMode = true
+			m.historyIdx = len(m.searchHistory)
 			return m, nil
 
 		case "esc":
+			// Leave an agent's or a group's rows before clearing the search
+			if m.drillAgent != nil {
+				m.leaveAgent()
+				return m, nil
+			}
+			if m.drillGroup != nil {
+				m.leaveGroup()
+				return m, nil
+			}
 			// Clear search if active
 			if m.searchQuery != "" {
 				m.searchQuery = ""
@@ -285,9 +444,16 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "3":
-			// Only allow Aggregated view if data is hierarchical
-			if m.isTreeData {
-				m.setViewMode(AggregatedView)
+			m.setViewMode(AggregatedView)
+
+		case "4":
+			m.setViewMode(GroupView)
+
+		case "g":
+			if m.viewMode == GroupView {
+				m.openColumnPicker(pickGroupColumns)
+			} else if m.viewMode == AggregatedView {
+				m.openColumnPicker(pickStackColumns)
 			}
 
 		case "up", "k":
@@ -299,16 +465,28 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "right", "l":
 			if m.viewMode == TreeView || m.viewMode == AggregatedView {
 				m.treeView.Expand()
+			} else if m.viewMode == TableView {
+				m.moveColumnFocus(1)
 			}
 
 		case "left", "h":
 			if m.viewMode == TreeView || m.viewMode == AggregatedView {
 				m.treeView.Collapse()
+			} else if m.viewMode == TableView {
+				m.moveColumnFocus(-1)
+			}
+
+		case "s", "S":
+			// S adds the column to the current sort instead of replacing it
+			if m.viewMode == TableView {
+				m.cycleSort(msg.String() == "S")
 			}
 
 		case "enter", " ":
 			if m.viewMode == TableView {
 				m.showDetailView()
+			} else if m.viewMode == GroupView {
+				m.enterGroup()
 			} else {
 				m.toggleExpand()
 			}
@@ -321,12 +499,36 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "c":
 			if m.viewMode == TreeView || m.viewMode == AggregatedView {
 				m.treeView.CollapseAll()
+			} else if m.viewMode == TableView {
+				m.openColumnPicker(pickTableColumns)
 			}
 
 		case "a":
 			if m.viewMode == AggregatedView {
 				m.showAnomaliesOnly = !m.showAnomaliesOnly
-				m.rebuildAggregatedTree()
+				m.refreshAggregatedTree()
+			}
+
+		case "A":
+			if m.viewMode == AggregatedView {
+				if cmd := m.openAgentPanel(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+
+		case "x":
+			m.markSelection()
+
+		case "X":
+			m.clearMarks()
+
+		case "R":
+			cmds = append(cmds, m.openFollowUp())
+
+		case "o":
+			if m.viewMode == AggregatedView {
+				m.rarestFirst = !m.rarestFirst
+				m.refreshAggregatedTree()
 			}
 		}
 
@@ -341,6 +543,25 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
+	case scriptsMsg:
+		m.handleScripts(msg)
+
+	case followUpStartedMsg:
+		return m.handleFollowUpStarted(msg)
+
+	case openExecutionMsg:
+		return m.switchExecution(msg.executionID)
+
+	case agentsMsg:
+		m.fleetLoading = false
+		m.fleetErr = msg.err
+		if msg.err == nil {
+			m.fleet = make(map[string]api.Agent, len(msg.agents))
+			for _, agent := range msg.agents {
+				m.fleet[agent.AgentID] = agent
+			}
+		}
+
 	case executionMsg:
 		if msg.err != nil {
 			m.err = msg.err
@@ -360,11 +581,22 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Keep refreshing if not complete
 			if msg.status.State != "Completed" && msg.status.State != "Failed" {
 				cmds = append(cmds, tick())
+			} else if len(m.results) < msg.status.Received {
+				// Pick up results that arrived after the last refresh
+				cmds = append(cmds, m.refreshResults())
 			}
 		}
 
+	case fetchProgressMsg:
+		// Progress is only shown for the initial load, not background refreshes
+		if m.loading {
+			m.fetchProgress = msg.progress
+			cmds = append(cmds, waitForFetchProgress(msg.executionID, msg.ch))
+		}
+
 	case resultsMsg:
 		m.loading = false
+		m.refreshing = false
 		if msg.err != nil {
 			m.err = msg.err
 		} else {
@@ -372,12 +604,27 @@ func (m *ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.processResults()
 		}
 
+	case newResultsMsg:
+		switch {
+		case errors.Is(msg.err, api.ErrResultsChanged):
+			// Loaded results no longer line up with the server; reload everything
+			cmds = append(cmds, m.fetchResults())
+		case msg.err != nil:
+			m.refreshing = false
+			m.err = msg.err
+		default:
+			m.refreshing = false
+			if len(msg.results) > 0 {
+				m.mergeResults(msg.results)
+			}
+		}
+
 	case tickMsg:
 		// Refresh status
 		cmds = append(cmds, m.fetchStatus)
-		// Also refresh results if not complete
+		// Also fetch new results if not complete
 		if m.status != nil && m.status.State != "Completed" && m.status.State != "Failed" {
-			cmds = append(cmds, m.fetchResults)
+			cmds = append(cmds, m.refreshResults())
 		}
 	}
 
@@ -390,6 +637,8 @@ func (m *ResultsModel) nextTab() {
 	m.currentTab = TabIndex(m.tabBar.GetActive())
 	m.tableSelectedIdx = 0
 	m.tableScrollOffset = 0
+	m.drillGroup = nil
+	m.drillAgent = nil
 
 	// Rebuild table and tree for new tab
 	m.buildTableData()
@@ -403,6 +652,9 @@ func (m *ResultsModel) nextTab() {
 	if m.searchQuery != "" {
 		m.applySearchFilter()
 	}
+	if m.viewMode == GroupView {
+		m.rebuildGroups()
+	}
 }
 
 // setViewMode changes the view mode
@@ -429,6 +681,8 @@ func (m *ResultsModel) setViewMode(mode ViewMode) {
 		} else {
 			m.rebuildTreeFromResults()
 		}
+	} else if mode == GroupView {
+		m.rebuildGroups()
 	}
 
 	// Re-apply search filter if active
@@ -447,6 +701,11 @@ func (m *ResultsModel) navigateUp() {
 		}
 	case TreeView, AggregatedView:
 		m.treeView.MoveUp()
+	case GroupView:
+		if m.groupSelectedIdx > 0 {
+			m.groupSelectedIdx--
+			m.ensureGroupVisible()
+		}
 	}
 }
 
@@ -461,7 +720,312 @@ func (m *ResultsModel) navigateDown() {
 		}
 	case TreeView, AggregatedView:
 		m.treeView.MoveDown()
+	case GroupView:
+		if m.groupSelectedIdx < len(m.groups)-1 {
+			m.groupSelectedIdx++
+			m.ensureGroupVisible()
+		}
+	}
+}
+
+// focusedColumnIndex returns the index of the focused column, defaulting to
+// the first column when it is unset or no longer present
+func (m *ResultsModel) focusedColumnIndex() int {
+	for i, col := range m.visibleColumns() {
+		if col == m.focusedColumn {
+			return i
+		}
+	}
+	return 0
+}
+
+// moveColumnFocus moves the focused table column left or right
+func (m *ResultsModel) moveColumnFocus(delta int) {
+	columns := m.visibleColumns()
+	if len(columns) == 0 {
+		return
+	}
+	idx := m.focusedColumnIndex() + delta
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(columns) {
+		idx = len(columns) - 1
+	}
+	m.focusedColumn = columns[idx]
+	m.ensureColumnVisible()
+}
+
+// visibleColumns returns the columns shown in the table: the chosen layout,
+// or every column when none was chosen. Chosen columns that no result has
+// yet are left out.
+func (m *ResultsModel) visibleColumns() []string {
+	if m.columnLayout == nil {
+		return m.tableColumns
+	}
+	var columns []string
+	for _, col := range m.columnLayout {
+		if m.columnSet[col] {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// columnWindow returns the range of columns that fit beside the pinned AGENT
+// column, starting at the scroll offset. At least one column is shown.
+func (m *ResultsModel) columnWindow(columns []string, widths map[string]int) (int, int) {
+	first := m.columnOffset
+	if first > len(columns)-1 {
+		first = max(len(columns)-1, 0)
+	}
+
+	used := widths["_agent"]
+	end := first
+	for ; end < len(columns); end++ {
+		width := widths[columns[end]] + 3 // Column + separator
+		if used+width > m.width-2 && end > first {
+			break
+		}
+		used += width
+	}
+	return first, end
+}
+
+// ensureColumnVisible scrolls horizontally to keep the focused column visible
+func (m *ResultsModel) ensureColumnVisible() {
+	columns := m.visibleColumns()
+	idx := m.focusedColumnIndex()
+	if idx < m.columnOffset {
+		m.columnOffset = idx
+		return
+	}
+
+	widths := m.calculateColumnWidths()
+	for m.columnOffset < idx {
+		if _, end := m.columnWindow(columns, widths); idx < end {
+			break
+		}
+		m.columnOffset++
+	}
+}
+
+// openColumnPicker lists the checked columns in order, then the others. The
+// checked columns are the visible, group-by or stacked columns, depending on
+// what the picker is for.
+func (m *ResultsModel) openColumnPicker(target pickerTarget) {
+	visible := m.visibleColumns()
+	switch target {
+	case pickGroupColumns:
+		visible = m.groupColumns
+	case pickStackColumns:
+		visible = m.stackColumns
+	}
+	m.pickerFor = target
+	shown := make(map[string]bool, len(visible))
+	m.columnPicker = []pickerColumn{}
+	for _, col := range visible {
+		shown[col] = true
+		m.columnPicker = append(m.columnPicker, pickerColumn{Name: col, Visible: true})
+	}
+	for _, col := range m.tableColumns {
+		if !shown[col] {
+			m.columnPicker = append(m.columnPicker, pickerColumn{Name: col})
+		}
+	}
+	m.columnPickerIdx = 0
+	m.helpBar.SetItems(components.ColumnPickerHelpItems())
+}
+
+// updateColumnPicker handles keys while the column picker is open
+func (m *ResultsModel) updateColumnPicker(msg tea.KeyMsg) tea.Cmd {
+	picker := m.columnPicker
+	idx := m.columnPickerIdx
+
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q", "c":
+		m.closeColumnPicker()
+	case "up", "k":
+		if idx > 0 {
+			m.columnPickerIdx--
+		}
+	case "down", "j":
+		if idx < len(picker)-1 {
+			m.columnPickerIdx++
+		}
+	case " ", "x":
+		if len(picker) > 0 {
+			picker[idx].Visible = !picker[idx].Visible
+		}
+	case "K", "shift+up":
+		// Move the column left in the table
+		if idx > 0 {
+			picker[idx-1], picker[idx] = picker[idx], picker[idx-1]
+			m.columnPickerIdx--
+		}
+	case "J", "shift+down":
+		if idx < len(picker)-1 {
+			picker[idx+1], picker[idx] = picker[idx], picker[idx+1]
+			m.columnPickerIdx++
+		}
+	case "a":
+		for i := range picker {
+			picker[i].Visible = true
+		}
+	case "r":
+		// Back to every column in the default order (or none for group-by
+		// and stacking)
+		m.columnPicker = m.columnPicker[:0]
+		for _, col := range m.tableColumns {
+			m.columnPicker = append(m.columnPicker, pickerColumn{Name: col, Visible: m.pickerFor == pickTableColumns})
+		}
+		m.columnPickerIdx = 0
+	case "enter":
+		m.applyColumnPicker()
+	}
+	return nil
+}
+
+// applyColumnPicker makes the picked columns the layout. Picking every column
+// in the default order goes back to showing all columns, including ones
+// that arrive later.
+func (m *ResultsModel) applyColumnPicker() {
+	var layout []string
+	for _, col := range m.columnPicker {
+		if col.Visible {
+			layout = append(layout, col.Name)
+		}
+	}
+	if m.pickerFor == pickStackColumns && (len(layout) > 0 || m.isTreeData) {
+		// Stacking by no columns goes back to merging tree paths
+		m.stackColumns = layout
+		m.refreshAggregatedTree()
+		m.closeColumnPicker()
+		return
+	}
+	if len(layout) == 0 {
+		// At least one column stays visible
+		return
+	}
+	if m.pickerFor == pickGroupColumns {
+		m.groupColumns = layout
+		m.groupSelectedIdx = 0
+		m.groupScrollOffset = 0
+		m.rebuildGroups()
+		m.closeColumnPicker()
+		return
+	}
+	if slices.Equal(layout, m.tableColumns) {
+		layout = nil
+	}
+
+	if !slices.Equal(layout, m.columnLayout) || (layout == nil) != (m.columnLayout == nil) {
+		m.columnLayout = layout
+		m.columnsChanged = true
+	}
+	m.columnOffset = 0
+	m.ensureColumnVisible()
+	m.closeColumnPicker()
+}
+
+func (m *ResultsModel) closeColumnPicker() {
+	m.columnPicker = nil
+	m.updateHelpItems()
+}
+
+// cycleSort moves the focused column to its next sort state: ascending,
+// descending, then unsorted. Unless multi is set the column becomes the only
+// sort key.
+func (m *ResultsModel) cycleSort(multi bool) {
+	columns := m.visibleColumns()
+	if len(columns) == 0 {
+		return
+	}
+	col := columns[m.focusedColumnIndex()]
+	m.focusedColumn = col
+
+	pos := -1
+	for i, key := range m.sortKeys {
+		if key.Column == col {
+			pos = i
+		}
+	}
+
+	var keys []resultset.SortKey
+	if multi {
+		keys = append(keys, m.sortKeys...)
+	}
+	switch {
+	case pos < 0:
+		keys = append(keys, resultset.SortKey{Column: col})
+	case !m.sortKeys[pos].Desc:
+		if multi {
+			keys[pos].Desc = true
+		} else {
+			keys = []resultset.SortKey{{Column: col, Desc: true}}
+		}
+	case multi:
+		keys = append(keys[:pos], keys[pos+1:]...)
+	}
+	m.sortKeys = keys
+
+	// Re-filtering restores server order before sorting, so that rows that
+	// compare equal keep their original order
+	var selected *TableRow
+	if m.tableSelectedIdx < len(m.filteredTableRows) {
+		row := m.filteredTableRows[m.tableSelectedIdx]
+		selected = &row
+	}
+	m.applySearchFilter()
+	if selected != nil {
+		m.selectTableRow(selected.AgentID, selected.RowIndex)
+	}
+}
+
+// sortTableRows sorts the filtered rows by the sort keys. The rows are copied
+// first because without a search they share their array with tableRows.
+func (m *ResultsModel) sortTableRows() {
+	if len(m.sortKeys) == 0 {
+		return
+	}
+	rows := make([]TableRow, len(m.filteredTableRows))
+	copy(rows, m.filteredTableRows)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return resultset.CompareBy(m.sortKeys, rows[i].Data, rows[j].Data) < 0
+	})
+	m.filteredTableRows = rows
+}
+
+// selectTableRow selects the row of an agent's item, if it is visible
+func (m *ResultsModel) selectTableRow(agentID string, rowIndex int) {
+	for i, row := range m.filteredTableRows {
+		if row.AgentID == agentID && row.RowIndex == rowIndex {
+			m.tableSelectedIdx = i
+			m.ensureTableVisible()
+			return
+		}
+	}
+}
+
+// sortIndicator returns the header suffix of a sorted column: an arrow, and
+// the key's position when sorting by more than one column
+func (m *ResultsModel) sortIndicator(col string) string {
+	for i, key := range m.sortKeys {
+		if key.Column != col {
+			continue
+		}
+		arrow := " ▲"
+		if key.Desc {
+			arrow = " ▼"
+		}
+		if len(m.sortKeys) > 1 {
+			arrow += fmt.Sprintf("%d", i+1)
+		}
+		return arrow
 	}
+	return ""
 }
 
 // toggleExpand expands/collapses current selection
@@ -510,18 +1074,11 @@ func (m *ResultsModel) showDetailView() {
 			b.WriteString("\n")
 		} else {
 			// Parse JSON and extract just the selected row's item
-			var data interface{}
-			if err := json.Unmarshal([]byte(originalResult.AnswerJSON), &data); err == nil {
-				// If it's an array, get just the item at RowIndex
-				var itemToShow interface{}
-				if arr, ok := data.([]interface{}); ok {
-					if row.RowIndex >= 0 && row.RowIndex < len(arr) {
-						itemToShow = arr[row.RowIndex]
-					} else {
-						itemToShow = data // Fallback to full array
-					}
-				} else {
-					itemToShow = data // Single object, show it
+			if data, err := resultset.Parse(originalResult.AnswerJSON); err == nil {
+				// Rows are numbered like the table, so get just the item at RowIndex
+				var itemToShow interface{} = data // Fallback to full answer
+				if items := resultset.NormalizeToRows(data); row.RowIndex >= 0 && row.RowIndex < len(items) {
+					itemToShow = items[row.RowIndex]
 				}
 
 				prettyJSON, _ := json.MarshalIndent(itemToShow, "", "  ")
@@ -535,7 +1092,7 @@ func (m *ResultsModel) showDetailView() {
 		for col, val := range row.Data {
 			b.WriteString(ui.HeaderStyle.Render(col + ":"))
 			b.WriteString("\n")
-			b.WriteString(wrapText(val, m.width-4))
+			b.WriteString(wrapText(val.String(), m.width-4))
 			b.WriteString("\n\n")
 		}
 	}
@@ -624,9 +1181,14 @@ func (m *ResultsModel) ensureTableVisible() {
 
 // processResults processes fetched results
 func (m *ResultsModel) processResults() {
+	expansion := m.treeView.ExpansionState()
+	selected := m.treeView.SelectedID()
+
 	// Separate errors
 	m.errors = nil
+	m.resultKeys = make([]string, 0, len(m.results))
 	for _, r := range m.results {
+		m.resultKeys = append(m.resultKeys, api.ResultKey(r))
 		if r.HasError {
 			m.errors = append(m.errors, r)
 		}
@@ -635,8 +1197,12 @@ func (m *ResultsModel) processResults() {
 	m.tabBar.UpdateCount(0, len(m.results)-len(m.errors))
 	m.tabBar.UpdateCount(1, len(m.errors))
 
-	// Build table rows from JSON
+	// Build table rows from JSON, keeping the selection in range
 	m.buildTableData()
+	if m.tableSelectedIdx >= len(m.filteredTableRows) {
+		m.tableSelectedIdx = max(len(m.filteredTableRows)-1, 0)
+		m.ensureTableVisible()
+	}
 
 	// Detect if tree view is applicable (before building trees)
 	m.detectTreeApplicability()
@@ -648,104 +1214,168 @@ func (m *ResultsModel) processResults() {
 	} else if m.viewMode == TreeView {
 		m.rebuildTreeFromResults()
 	}
+	if m.searchQuery != "" && (m.viewMode == TreeView || m.viewMode == AggregatedView) {
+		m.filterTreeView()
+	}
+
+	m.treeView.RestoreExpansion(expansion)
+	m.treeView.SelectByID(selected)
 }
 
-// buildTableData parses JSON results into flat table rows
-func (m *ResultsModel) buildTableData() {
-	m.tableRows = nil
-	m.tableColumns = nil
-	columnSet := make(map[string]bool)
+// mergeResults adds newly arrived results to the loaded data without
+// rebuilding existing rows and trees, keeping selection, expansion and search
+func (m *ResultsModel) mergeResults(newResults []api.ExecutionResult) {
+	expansion := m.treeView.ExpansionState()
+	selected := m.treeView.SelectedID()
+	hadResults := len(m.getCurrentResults()) > 0
+
+	var added []api.ExecutionResult
+	for _, r := range newResults {
+		m.results = append(m.results, r)
+		m.resultKeys = append(m.resultKeys, api.ResultKey(r))
+		if r.HasError {
+			m.errors = append(m.errors, r)
+		}
+		if r.HasError == (m.currentTab == ErrorsTab) {
+			added = append(added, r)
+		}
+	}
 
-	results := m.getCurrentResults()
+	m.tabBar.UpdateCount(0, len(m.results)-len(m.errors))
+	m.tabBar.UpdateCount(1, len(m.errors))
 
-	for _, r := range results {
-		// For errors, use RawStdError instead of AnswerJSON
-		if r.HasError {
-			errorMsg := r.RawStdError
-			if errorMsg == "" {
-				errorMsg = r.AnswerJSON // Fallback to AnswerJSON if no stderr
-			}
-			if errorMsg == "" {
-				errorMsg = "(no error message)"
+	if len(added) == 0 {
+		return
+	}
+
+	m.appendTableRows(added)
+
+	if !hadResults {
+		m.detectTreeApplicability()
+	}
+
+	// Per-agent trees exist once a tree view has been shown; keep them current
+	if len(m.agentTrees) > 0 || m.viewMode == TreeView || m.viewMode == AggregatedView {
+		for _, r := range added {
+			if tree := m.buildAgentTree(r); tree != nil {
+				m.agentTrees = append(m.agentTrees, tree)
 			}
-			m.tableRows = append(m.tableRows, TableRow{
-				AgentName: r.AgentName,
-				AgentID:   r.AgentID,
-				Data:      map[string]string{"Error": truncateString(errorMsg, 200)},
-				RowIndex:  0,
-				HasError:  true,
-			})
-			columnSet["Error"] = true
-			continue
 		}
+	}
 
-		// Parse JSON
-		var data interface{}
-		if err := json.Unmarshal([]byte(r.AnswerJSON), &data); err != nil {
-			// Not valid JSON - create a single row with raw value
-			m.tableRows = append(m.tableRows, TableRow{
-				AgentName: r.AgentName,
-				AgentID:   r.AgentID,
-				Data:      map[string]string{"Value": truncateString(r.AnswerJSON, 100)},
-				RowIndex:  0,
-				HasError:  r.HasError,
-			})
-			columnSet["Value"] = true
-			continue
-		}
+	switch {
+	case m.viewMode != TreeView && m.viewMode != AggregatedView:
+		return
+	case m.searchQuery != "":
+		m.filterTreeView()
+	case m.viewMode == AggregatedView:
+		m.rebuildAggregatedTree()
+	default:
+		m.treeView.SetAgents(m.agentTrees)
+	}
 
-		// Normalize to array of objects
-		rows := normalizeToRows(data)
+	m.treeView.RestoreExpansion(expansion)
+	m.treeView.SelectByID(selected)
+}
 
-		if len(rows) == 0 {
-			// Empty result
-			m.tableRows = append(m.tableRows, TableRow{
-				AgentName: r.AgentName,
-				AgentID:   r.AgentID,
-				Data:      map[string]string{},
-				RowIndex:  0,
-				HasError:  r.HasError,
-			})
-			continue
-		}
+// buildTableData parses JSON results into flat table rows
+func (m *ResultsModel) buildTableData() {
+	m.tableRows = nil
+	m.tableColumns = nil
+	m.columnSet = make(map[string]bool)
+	m.columnKinds = make(map[string]resultset.Kind)
+	m.filteredTableRows = nil
 
-		// Create a row for each item
-		for i, row := range rows {
-			flatRow := flattenObject(row, "")
-			for k := range flatRow {
+	m.appendTableRows(m.getCurrentResults())
+}
+
+// appendTableRows parses results into table rows appended to the existing ones,
+// filtering only the new rows when a search is active
+func (m *ResultsModel) appendTableRows(results []api.ExecutionResult) {
+	if m.columnSet == nil {
+		m.columnSet = make(map[string]bool)
+		m.columnKinds = make(map[string]resultset.Kind)
+	}
+	columnSet := m.columnSet
+	first := len(m.tableRows)
+
+	// With a sort active new rows may land above the selection, so the
+	// selected row is found again afterwards
+	var selected *TableRow
+	if m.tableSelectedIdx < len(m.filteredTableRows) {
+		row := m.filteredTableRows[m.tableSelectedIdx]
+		selected = &row
+	}
+
+	for _, r := range results {
+		for _, row := range resultset.Rows(r) {
+			data := row.Data
+			// Long errors and raw answers are shortened for display
+			if row.HasError {
+				data = map[string]resultset.Value{"Error": resultset.StringValue(truncateString(data["Error"].String(), 200))}
+			} else if row.Raw {
+				data = map[string]resultset.Value{"Value": resultset.StringValue(truncateString(data["Value"].String(), 100))}
+			}
+			for k, v := range data {
 				columnSet[k] = true
+				m.columnKinds[k] = resultset.MergeKind(m.columnKinds[k], v.Kind)
 			}
 
 			m.tableRows = append(m.tableRows, TableRow{
-				AgentName: r.AgentName,
-				AgentID:   r.AgentID,
-				Data:      flatRow,
-				RowIndex:  i,
-				HasError:  r.HasError,
+				AgentName: row.AgentName,
+				AgentID:   row.AgentID,
+				Data:      data,
+				RowIndex:  row.Index,
+				HasError:  row.HasError,
 			})
 		}
 	}
 
 	// Sort columns with common ones first
-	m.tableColumns = sortColumns(columnSet)
+	m.tableColumns = resultset.SortColumns(columnSet)
 
-	// Initialize filtered rows (apply current search if any)
-	if m.searchQuery != "" {
-		m.applySearchFilter()
-	} else {
+	if m.viewMode == GroupView {
+		m.rebuildGroups()
+	}
+
+	// Extend filtered rows (apply current search if any)
+	if m.searchQuery == "" && m.drillGroup == nil && m.drillAgent == nil {
 		m.filteredTableRows = m.tableRows
 		m.searchMatches = len(m.tableRows)
+	} else {
+		for _, row := range m.tableRows[first:] {
+			if m.rowMatchesSearch(row) {
+				m.filteredTableRows = append(m.filteredTableRows, row)
+			}
+		}
+		if m.viewMode == TableView {
+			m.searchMatches = len(m.filteredTableRows)
+		}
+	}
+	m.sortTableRows()
+	if selected != nil {
+		m.selectTableRow(selected.AgentID, selected.RowIndex)
 	}
 }
 
-// applySearchFilter filters data based on search query
+// applySearchFilter filters data based on search query. While the query has
+// a syntax error the last valid filter stays applied.
 func (m *ResultsModel) applySearchFilter() {
-	query := strings.ToLower(m.searchQuery)
+	filter, err := resultset.ParseSearch(m.searchQuery)
+	m.searchErr = err
+	if err != nil {
+		return
+	}
+	m.searchFilter = filter
+	if m.viewMode == GroupView {
+		m.rebuildGroups()
+	}
 
-	if query == "" {
+	if filter == nil && m.drillGroup == nil && m.drillAgent == nil {
 		// No filter - show all
 		m.filteredTableRows = m.tableRows
 		m.searchMatches = len(m.tableRows)
+		m.sortTableRows()
 
 		// Reset tree view to show all
 		if m.viewMode == TreeView {
@@ -759,11 +1389,12 @@ func (m *ResultsModel) applySearchFilter() {
 	// Filter table rows
 	m.filteredTableRows = nil
 	for _, row := range m.tableRows {
-		if m.rowMatchesSearch(row, query) {
+		if m.rowMatchesSearch(row) {
 			m.filteredTableRows = append(m.filteredTableRows, row)
 		}
 	}
 	m.searchMatches = len(m.filteredTableRows)
+	m.sortTableRows()
 
 	// Reset selection if out of bounds
 	if m.tableSelectedIdx >= len(m.filteredTableRows) {
@@ -773,29 +1404,62 @@ func (m *ResultsModel) applySearchFilter() {
 
 	// For tree views, filter and rebuild
 	if m.viewMode == TreeView || m.viewMode == AggregatedView {
-		m.filterTreeView(query)
+		m.filterTreeView()
+	}
+}
+
+// addSearchHistory records an applied query, skipping repeats of the last one
+func (m *ResultsModel) addSearchHistory(query string) {
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+	if n := len(m.searchHistory); n == 0 || m.searchHistory[n-1] != query {
+		m.searchHistory = append(m.searchHistory, query)
 	}
+	m.historyIdx = len(m.searchHistory)
 }
 
-// rowMatchesSearch checks if a table row matches the search query
-func (m *ResultsModel) rowMatchesSearch(row TableRow, query string) bool {
-	// Check agent name
-	if strings.Contains(strings.ToLower(row.AgentName), query) {
-		return true
+// browseSearchHistory replaces the query with an older (-1) or newer (+1)
+// one; moving past the newest brings back what was being typed
+func (m *ResultsModel) browseSearchHistory(delta int) {
+	idx := m.historyIdx + delta
+	if idx < 0 || idx > len(m.searchHistory) {
+		return
+	}
+	if m.historyIdx == len(m.searchHistory) {
+		m.searchDraft = m.searchQuery
 	}
+	m.historyIdx = idx
 
-	// Check all column values
-	for _, value := range row.Data {
-		if strings.Contains(strings.ToLower(value), query) {
-			return true
-		}
+	if idx == len(m.searchHistory) {
+		m.searchQuery = m.searchDraft
+	} else {
+		m.searchQuery = m.searchHistory[idx]
 	}
+	m.applySearchFilter()
+}
 
-	return false
+// rowMatchesSearch checks if a table row matches the search query, and
+// belongs to the agent or group being drilled into
+func (m *ResultsModel) rowMatchesSearch(row TableRow) bool {
+	if m.drillAgent != nil && row.AgentID != m.drillAgent.ID {
+		return false
+	}
+	if m.drillGroup != nil && resultset.GroupKey(m.groupColumns, row.Data) != m.drillGroup.ID {
+		return false
+	}
+	return m.searchFilter.Match(row.AgentName, row.Data)
 }
 
 // filterTreeView filters tree nodes based on search query
-func (m *ResultsModel) filterTreeView(query string) {
+func (m *ResultsModel) filterTreeView() {
+	query := m.searchFilter
+	if m.viewMode == AggregatedView && m.stacking() {
+		// Stacks are counted over the rows matching the search
+		m.rebuildAggregatedTree()
+		m.searchMatches = countNodes(m.aggregateTree)
+		return
+	}
 	if m.viewMode == AggregatedView {
 		// For aggregated view, filter the aggregated tree
 		m.rebuildAggregatedTree()
@@ -816,7 +1480,7 @@ func (m *ResultsModel) filterTreeView(query string) {
 		var filteredAgents []*components.AgentTree
 		for _, agent := range m.agentTrees {
 			filteredRoots := filterTreeNodes(agent.Roots, query)
-			if len(filteredRoots) > 0 || strings.Contains(strings.ToLower(agent.AgentName), query) {
+			if len(filteredRoots) > 0 || query.Match(agent.AgentName, nil) {
 				filteredAgent := &components.AgentTree{
 					AgentID:   agent.AgentID,
 					AgentName: agent.AgentName,
@@ -838,11 +1502,11 @@ func (m *ResultsModel) filterTreeView(query string) {
 }
 
 // filterTreeNodes recursively filters tree nodes that match the query
-func filterTreeNodes(nodes []*components.TreeNode, query string) []*components.TreeNode {
+func filterTreeNodes(nodes []*components.TreeNode, query *resultset.Query) []*components.TreeNode {
 	var filtered []*components.TreeNode
 
 	for _, node := range nodes {
-		nodeMatches := strings.Contains(strings.ToLower(node.Label), query)
+		nodeMatches := query.MatchLabel(node.Label)
 
 		// Filter children recursively
 		filteredChildren := filterTreeNodes(node.Children, query)
@@ -860,7 +1524,9 @@ func filterTreeNodes(nodes []*components.TreeNode, query string) []*components.T
 				Count:      node.Count,
 				TotalCount: node.TotalCount,
 				AgentNames: node.AgentNames,
+				AgentIDs:   node.AgentIDs,
 				IsAnomaly:  node.IsAnomaly,
+				Rarity:     node.Rarity,
 			}
 			filtered = append(filtered, filteredNode)
 		}
@@ -879,10 +1545,10 @@ func countNodes(nodes []*components.TreeNode) int {
 }
 
 // countMatchingNodes counts nodes that match the query
-func countMatchingNodes(nodes []*components.TreeNode, query string) int {
+func countMatchingNodes(nodes []*components.TreeNode, query *resultset.Query) int {
 	count := 0
 	for _, node := range nodes {
-		if strings.Contains(strings.ToLower(node.Label), query) {
+		if query.MatchLabel(node.Label) {
 			count++
 		}
 		count += countMatchingNodes(node.Children, query)
@@ -890,94 +1556,6 @@ func countMatchingNodes(nodes []*components.TreeNode, query string) int {
 	return count
 }
 
-// normalizeToRows converts JSON data to array of maps
-func normalizeToRows(data interface{}) []map[string]interface{} {
-	switch v := data.(type) {
-	case []interface{}:
-		var rows []map[string]interface{}
-		for _, item := range v {
-			if m, ok := item.(map[string]interface{}); ok {
-				rows = append(rows, m)
-			}
-		}
-		return rows
-	case map[string]interface{}:
-		return []map[string]interface{}{v}
-	}
-	return nil
-}
-
-// flattenObject flattens nested objects with dot notation
-func flattenObject(obj map[string]interface{}, prefix string) map[string]string {
-	result := make(map[string]string)
-
-	for key, value := range obj {
-		fullKey := key
-		if prefix != "" {
-			fullKey = prefix + "." + key
-		}
-
-		switch v := value.(type) {
-		case map[string]interface{}:
-			// Flatten nested objects
-			nested := flattenObject(v, fullKey)
-			for k, val := range nested {
-				result[k] = val
-			}
-		case []interface{}:
-			// For arrays, show count or first few items
-			if len(v) == 0 {
-				result[fullKey] = "[]"
-			} else if len(v) <= 3 {
-				// Show short arrays inline
-				var items []string
-				for _, item := range v {
-					items = append(items, fmt.Sprintf("%v", item))
-				}
-				result[fullKey] = strings.Join(items, ", ")
-			} else {
-				result[fullKey] = fmt.Sprintf("[%d items]", len(v))
-			}
-		case nil:
-			result[fullKey] = ""
-		default:
-			result[fullKey] = fmt.Sprintf("%v", v)
-		}
-	}
-
-	return result
-}
-
-// sortColumns returns columns sorted with priority columns first
-func sortColumns(columnSet map[string]bool) []string {
-	// Priority columns that should appear first
-	priority := []string{"Name", "name", "User", "user", "Group", "group", "Path", "path",
-		"Value", "value", "Status", "status", "Type", "type", "ID", "id", "Id"}
-
-	var columns []string
-	added := make(map[string]bool)
-
-	// Add priority columns first
-	for _, p := range priority {
-		if columnSet[p] && !added[p] {
-			columns = append(columns, p)
-			added[p] = true
-		}
-	}
-
-	// Add remaining columns alphabetically
-	var remaining []string
-	for col := range columnSet {
-		if !added[col] {
-			remaining = append(remaining, col)
-		}
-	}
-	sort.Strings(remaining)
-	columns = append(columns, remaining...)
-
-	return columns
-}
-
 // detectTreeApplicability checks if tree/aggregated views make sense for this data
 func (m *ResultsModel) detectTreeApplicability() {
 	// Tree view is applicable if:
@@ -993,155 +1571,20 @@ func (m *ResultsModel) detectTreeApplicability() {
 	}
 
 	// Check first result for nested structure
-	var data interface{}
-	if err := json.Unmarshal([]byte(results[0].AnswerJSON), &data); err != nil {
+	data, err := resultset.Parse(results[0].AnswerJSON)
+	if err != nil {
 		m.viewModeBar.SetTreeEnabled(false)
 		return
 	}
 
-	m.isTreeData = hasNestedStructure(data)
+	m.isTreeData = resultset.HasNestedStructure(data)
 	m.viewModeBar.SetTreeEnabled(m.isTreeData)
 }
 
-// hasNestedStructure checks if data contains nested objects/arrays
-func hasNestedStructure(data interface{}) bool {
-	switch v := data.(type) {
-	case []interface{}:
-		if len(v) == 0 {
-			return false
-		}
-		// Check if array items have nested objects/arrays
-		if first, ok := v[0].(map[string]interface{}); ok {
-			for _, val := range first {
-				switch val.(type) {
-				case map[string]interface{}, []interface{}:
-					return true
-				}
-			}
-			// Also check for self-referential fields (parent/child)
-			return hasSelfReferentialFields(v)
-		}
-	case map[string]interface{}:
-		for _, val := range v {
-			switch val.(type) {
-			case map[string]interface{}, []interface{}:
-				return true
-			}
-		}
-	}
-	return false
-}
-
-// hasSelfReferentialFields detects self-referential tree structure by analyzing data values
-// This matches the web frontend's approach: look for a field where values reference another field's values
-func hasSelfReferentialFields(rows []interface{}) bool {
-	if len(rows) < 2 {
-		return false
-	}
-
-	// Convert to maps
-	var rowMaps []map[string]interface{}
-	for _, r := range rows {
-		if m, ok := r.(map[string]interface{}); ok {
-			rowMaps = append(rowMaps, m)
-		}
-	}
-
-	if len(rowMaps) < 2 {
-		return false
-	}
-
-	// Get column names from first row
-	columns := make([]string, 0)
-	for k := range rowMaps[0] {
-		columns = append(columns, k)
-	}
-
-	if len(columns) < 2 {
-		return false
-	}
-
-	// Try each pair of columns to find id/parent relationship
-	for _, idCol := range columns {
-		// Collect all values in the potential ID column (as strings to avoid unhashable types)
-		idValues := make(map[string]bool)
-		for _, row := range rowMaps {
-			if val, ok := row[idCol]; ok && val != nil {
-				// Only use scalar values (strings, numbers) - skip maps and slices
-				strVal := toStringKey(val)
-				if strVal != "" {
-					idValues[strVal] = true
-				}
-			}
-		}
-
-		// Skip if no valid IDs
-		if len(idValues) == 0 {
-			continue
-		}
-
-		for _, parentCol := range columns {
-			if idCol == parentCol {
-				continue
-			}
-
-			// Count valid references:
-			// - null/nil/empty/0 = root node (valid)
-			// - references an existing ID (valid)
-			validRefs := 0
-			hasRoot := false
-
-			for _, row := range rowMaps {
-				parentVal := row[parentCol]
-
-				// Check for root indicators
-				if parentVal == nil {
-					validRefs++
-					hasRoot = true
-				} else {
-					strVal := toStringKey(parentVal)
-					if strVal == "" || strVal == "0" {
-						validRefs++
-						hasRoot = true
-					} else if idValues[strVal] {
-						validRefs++
-					}
-				}
-			}
-
-			// Must have: 80%+ valid references AND at least one root
-			refRatio := float64(validRefs) / float64(len(rowMaps))
-			if refRatio >= 0.8 && hasRoot {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-// toStringKey converts a value to a string key, returning empty string for non-scalar types
-func toStringKey(val interface{}) string {
-	switch v := val.(type) {
-	case string:
-		return v
-	case float64:
-		return fmt.Sprintf("%v", v)
-	case int:
-		return fmt.Sprintf("%d", v)
-	case int64:
-		return fmt.Sprintf("%d", v)
-	case bool:
-		return fmt.Sprintf("%t", v)
-	default:
-		// Maps, slices, etc. - not usable as keys
-		return ""
-	}
-}
-
 // rebuildTreeFromResults builds tree view from results (per-agent trees)
 func (m *ResultsModel) rebuildTreeFromResults() {
 	m.agentTrees = nil
+	m.answerTrees = make(map[string][]*resultset.Node)
 
 	results := m.getCurrentResults()
 	for _, r := range results {
@@ -1158,6 +1601,7 @@ func (m *ResultsModel) rebuildTreeFromResults() {
 // rebuildTreeFromErrors builds tree view from error results
 func (m *ResultsModel) rebuildTreeFromErrors() {
 	m.agentTrees = nil
+	m.answerTrees = make(map[string][]*resultset.Node)
 
 	for _, r := range m.errors {
 		tree := m.buildAgentTree(r)
@@ -1178,185 +1622,93 @@ func (m *ResultsModel) buildAgentTree(result api.ExecutionResult) *components.Ag
 	}
 
 	// Parse JSON result
-	var data interface{}
-	if err := json.Unmarshal([]byte(result.AnswerJSON), &data); err != nil {
+	data, err := resultset.Parse(result.AnswerJSON)
+	if err != nil {
 		// If not valid JSON, create a simple node
 		tree.Roots = []*components.TreeNode{{
 			ID:    "0",
 			Label: truncateString(result.AnswerJSON, 100),
 		}}
 		tree.NodeCount = 1
+		delete(m.answerTrees, result.AgentID)
 		return tree
 	}
 
 	// Build tree from JSON
-	nodeCount := 0
-	tree.Roots = buildTreeNodes(data, 0, &nodeCount)
-	tree.NodeCount = nodeCount
+	nodes := resultset.Tree(data)
+	m.answerTrees[result.AgentID] = nodes
+	tree.Roots = buildTreeNodes(nodes, nil, 0)
+	tree.NodeCount = resultset.CountNodes(nodes)
 
 	return tree
 }
 
-// buildTreeNodes recursively builds tree nodes from JSON data
-func buildTreeNodes(data interface{}, depth int, count *int) []*components.TreeNode {
-	var nodes []*components.TreeNode
+// buildTreeNodes converts answer nodes to tree view nodes identified by their path
+func buildTreeNodes(nodes []*resultset.Node, prefix resultset.Path, depth int) []*components.TreeNode {
+	treeNodes := make([]*components.TreeNode, 0, len(nodes))
+	seen := make(map[string]int)
 
-	switch v := data.(type) {
-	case map[string]interface{}:
-		for key, value := range v {
-			*count++
-			node := &components.TreeNode{
-				ID:       fmt.Sprintf("%d-%s", *count, key),
-				Label:    key,
-				Depth:    depth,
-				Expanded: false,
-			}
-
-			switch child := value.(type) {
-			case map[string]interface{}, []interface{}:
-				node.Children = buildTreeNodes(child, depth+1, count)
-			default:
-				// Leaf value
-				node.Label = fmt.Sprintf("%s: %v", key, value)
-			}
-
-			nodes = append(nodes, node)
+	for _, node := range nodes {
+		path := prefix.Child(node.Key)
+		id := path.String()
+		// Siblings with the same label (two processes with one name) stay distinct
+		if n := seen[id]; n > 0 {
+			id = fmt.Sprintf("%s#%d", id, n)
 		}
+		seen[path.String()]++
 
-	case []interface{}:
-		for i, item := range v {
-			*count++
-			node := &components.TreeNode{
-				ID:       fmt.Sprintf("%d-[%d]", *count, i),
-				Depth:    depth,
-				Expanded: false,
-			}
-
-			switch child := item.(type) {
-			case map[string]interface{}:
-				// Try to find a good label from the map
-				label := findLabel(child)
-				if label == "" {
-					label = fmt.Sprintf("[%d]", i)
-				}
-				node.Label = label
-				node.Children = buildTreeNodes(child, depth+1, count)
-			case []interface{}:
-				node.Label = fmt.Sprintf("[%d] (%d items)", i, len(child))
-				node.Children = buildTreeNodes(child, depth+1, count)
-			default:
-				node.Label = fmt.Sprintf("[%d]: %v", i, item)
-			}
-
-			nodes = append(nodes, node)
-		}
+		treeNodes = append(treeNodes, &components.TreeNode{
+			ID:       id,
+			Label:    node.Label,
+			Depth:    depth,
+			Expanded: false,
+			Children: buildTreeNodes(node.Children, path, depth+1),
+		})
 	}
 
-	return nodes
-}
-
-// findLabel tries to find a good label from a map
-func findLabel(m map[string]interface{}) string {
-	// Common label keys
-	labelKeys := []string{"name", "Name", "label", "Label", "title", "Title", "path", "Path", "key", "Key", "id", "Id", "ID"}
-	for _, key := range labelKeys {
-		if val, ok := m[key]; ok {
-			if s, ok := val.(string); ok && s != "" {
-				return s
-			}
-		}
-	}
-	return ""
+	return treeNodes
 }
 
 // rebuildAggregatedTree builds the aggregated tree view
 func (m *ResultsModel) rebuildAggregatedTree() {
+	if m.stacking() {
+		m.rebuildStackTree()
+		return
+	}
+
 	totalAgents := len(m.agentTrees)
 	if totalAgents == 0 {
 		return
 	}
 
 	// Build aggregated tree by path
-	pathCounts := make(map[string]*aggregatedPath)
+	aggregator := resultset.NewAggregator()
 	for _, agentTree := range m.agentTrees {
-		collectPaths(agentTree.Roots, "", agentTree.AgentName, pathCounts)
+		aggregator.Add(agentTree.AgentID, agentTree.AgentName, m.answerTrees[agentTree.AgentID])
 	}
 
 	// Convert to tree nodes with counts
-	m.aggregateTree = buildAggregatedNodes(pathCounts, "", totalAgents, m.showAnomaliesOnly)
+	m.aggregateTree = buildAggregatedNodes(aggregator.Sorted(m.rarestFirst), totalAgents, m.anomalyThreshold.Limit(totalAgents), m.showAnomaliesOnly)
 
 	// Create single agent tree for the aggregated view
 	aggregatedAgent := &components.AgentTree{
 		AgentID:   "aggregated",
 		AgentName: fmt.Sprintf("All Agents (%d)", totalAgents),
 		Roots:     m.aggregateTree,
-		NodeCount: len(pathCounts),
+		NodeCount: aggregator.Len(),
 		Expanded:  true,
 	}
 
 	m.treeView.SetAgents([]*components.AgentTree{aggregatedAgent})
 }
 
-type aggregatedPath struct {
-	label      string
-	count      int
-	agentNames []string
-	children   map[string]*aggregatedPath
-}
-
-func collectPaths(nodes []*components.TreeNode, prefix string, agentName string, paths map[string]*aggregatedPath) {
-	for _, node := range nodes {
-		path := prefix + "/" + node.Label
-		if paths[path] == nil {
-			paths[path] = &aggregatedPath{
-				label:    node.Label,
-				children: make(map[string]*aggregatedPath),
-			}
-		}
-		paths[path].count++
-		paths[path].agentNames = append(paths[path].agentNames, agentName)
-
-		if len(node.Children) > 0 {
-			collectPaths(node.Children, path, agentName, paths)
-		}
-	}
-}
-
-func buildAggregatedNodes(paths map[string]*aggregatedPath, prefix string, totalAgents int, anomaliesOnly bool) []*components.TreeNode {
+// buildAggregatedNodes converts aggregates to tree nodes; those found on at
+// most anomalyThreshold agents are anomalies
+func buildAggregatedNodes(aggregates []*resultset.Aggregate, totalAgents, anomalyThreshold int, anomaliesOnly bool) []*components.TreeNode {
 	var nodes []*components.TreeNode
 
-	// Find direct children of this prefix
-	childPaths := make(map[string]*aggregatedPath)
-	for path, p := range paths {
-		// Check if this is a direct child
-		if strings.HasPrefix(path, prefix+"/") {
-			remainder := strings.TrimPrefix(path, prefix+"/")
-			if !strings.Contains(remainder, "/") {
-				childPaths[path] = p
-			}
-		}
-	}
-
-	// Sort by count (descending)
-	type pathEntry struct {
-		path string
-		p    *aggregatedPath
-	}
-	var entries []pathEntry
-	for path, p := range childPaths {
-		entries = append(entries, pathEntry{path, p})
-	}
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].p.count > entries[j].p.count
-	})
-
-	anomalyThreshold := totalAgents / 10 // 10% threshold
-	if anomalyThreshold < 1 {
-		anomalyThreshold = 1
-	}
-
-	for _, e := range entries {
-		isAnomaly := e.p.count <= anomalyThreshold
+	for _, agg := range aggregates {
+		isAnomaly := agg.Count() <= anomalyThreshold
 
 		// Skip non-anomalies if showing anomalies only
 		if anomaliesOnly && !isAnomaly {
@@ -1364,17 +1716,19 @@ func buildAggregatedNodes(paths map[string]*aggregatedPath, prefix string, total
 		}
 
 		node := &components.TreeNode{
-			ID:         e.path,
-			Label:      e.p.label,
-			Count:      e.p.count,
+			ID:         agg.Path.String(),
+			Label:      agg.Label,
+			Count:      agg.Count(),
 			TotalCount: totalAgents,
-			AgentNames: e.p.agentNames,
+			AgentNames: agg.AgentNames,
+			AgentIDs:   agg.AgentIDs,
 			IsAnomaly:  isAnomaly,
+			Rarity:     resultset.Rarity(agg.Count(), totalAgents),
 			Expanded:   false,
 		}
 
 		// Recursively build children
-		node.Children = buildAggregatedNodes(paths, e.path, totalAgents, anomaliesOnly)
+		node.Children = buildAggregatedNodes(agg.Children, totalAgents, anomalyThreshold, anomaliesOnly)
 
 		nodes = append(nodes, node)
 	}
@@ -1401,6 +1755,8 @@ func (m *ResultsModel) updateHelpItems() {
 		m.helpBar.SetItems(components.TreeViewHelpItems())
 	case AggregatedView:
 		m.helpBar.SetItems(components.AggregatedViewHelpItems())
+	case GroupView:
+		m.helpBar.SetItems(components.GroupViewHelpItems())
 	}
 }
 
@@ -1430,15 +1786,25 @@ func (m *ResultsModel) View() string {
 		title = fmt.Sprintf(" Results: %s ", execID)
 	}
 	b.WriteString(ui.TitleStyle.Render(title))
+	// Cached or offline data is marked on the title line
+	if banner := sourceBanner(m.client, m.executionID); banner != "" {
+		b.WriteString(" " + banner)
+	}
 	b.WriteString("\n")
 
 	// Progress bar
 	if m.status != nil {
 		b.WriteString(m.progress.Render())
 		b.WriteString("\n")
-	} else if m.loading {
-		b.WriteString(m.spinner.View() + " Loading...")
-		b.WriteString("\n")
+	}
+	if m.loading {
+		if pages := formatFetchProgress(m.fetchProgress); pages != "" {
+			b.WriteString(m.spinner.View() + " Loading results... " + pages)
+			b.WriteString("\n")
+		} else if m.status == nil {
+			b.WriteString(m.spinner.View() + " Loading...")
+			b.WriteString("\n")
+		}
 	}
 
 	// Error display
@@ -1448,10 +1814,13 @@ func (m *ResultsModel) View() string {
 	}
 
 	// Search bar
-	if m.searchMode || m.searchQuery != "" {
+	if m.searchMode || m.searchQuery != "" || ((m.drillGroup != nil || m.drillAgent != nil) && m.viewMode == TableView) {
 		searchLine := m.renderSearchBar()
 		b.WriteString(searchLine)
 		b.WriteString("\n")
+	} else if len(m.markedAgents) > 0 {
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("%s %d agents marked (R to run a script on them, X to clear)", ui.MarkedAgent, len(m.markedAgents))))
+		b.WriteString("\n")
 	} else {
 		b.WriteString("\n")
 	}
@@ -1500,10 +1869,27 @@ func (m *ResultsModel) View() string {
 
 // renderContent renders the main content based on view mode
 func (m *ResultsModel) renderContent(height int) string {
+	if m.followUp != nil {
+		return m.renderFollowUp(height)
+	}
 	switch m.viewMode {
 	case TableView:
+		if m.columnPicker != nil {
+			return m.renderColumnPicker(height)
+		}
 		return m.renderTableView(height)
+	case GroupView:
+		if m.columnPicker != nil {
+			return m.renderColumnPicker(height)
+		}
+		return m.renderGroupView(height)
 	case TreeView, AggregatedView:
+		if m.columnPicker != nil {
+			return m.renderColumnPicker(height)
+		}
+		if m.agentPanel != nil {
+			return m.renderAgentPanel(height)
+		}
 		return m.treeView.Render()
 	}
 	return ""
@@ -1520,20 +1906,60 @@ func (m *ResultsModel) renderTableView(height int) string {
 
 	var b strings.Builder
 
-	// Calculate column widths
+	// Calculate column widths; AGENT is pinned and the other columns scroll
 	colWidths := m.calculateColumnWidths()
+	allColumns := m.visibleColumns()
+	first, end := m.columnWindow(allColumns, colWidths)
+	columns := allColumns[first:end]
 
-	// Header
+	// Header; the focused column is underlined and sorted columns show an arrow
 	var headerParts []string
 	headerParts = append(headerParts, fmt.Sprintf("%-*s", colWidths["_agent"], "AGENT"))
-	for _, col := range m.tableColumns {
+	styledHeader := ui.HeaderStyle.Render(headerParts[0])
+	focused := ""
+	if len(allColumns) > 0 {
+		focused = allColumns[m.focusedColumnIndex()]
+	}
+	for _, col := range columns {
 		width := colWidths[col]
-		headerParts = append(headerParts, fmt.Sprintf("%-*s", width, strings.ToUpper(col)))
+		indicator := m.sortIndicator(col)
+		name := strings.ToUpper(col)
+		if avail := width - utf8.RuneCountInString(indicator); avail > 3 {
+			name = truncateString(name, avail)
+		}
+
+		var part string
+		if m.columnKinds[col] == resultset.KindNumber {
+			part = fmt.Sprintf("%*s", width, name+indicator)
+		} else {
+			part = fmt.Sprintf("%-*s", width, name+indicator)
+		}
+		headerParts = append(headerParts, part)
+
+		style := ui.HeaderStyle
+		if col == focused {
+			style = ui.FocusedHeaderStyle
+		}
+		styledHeader += ui.HeaderStyle.Render(" │ ") + style.Render(part)
 	}
 	header := strings.Join(headerParts, " │ ")
-	b.WriteString(ui.HeaderStyle.Render(header))
+	b.WriteString(styledHeader)
 	b.WriteString("\n")
-	b.WriteString(ui.MutedStyle.Render(strings.Repeat("─", min(len(header)+10, m.width-2))))
+
+	// Columns scrolled out of view are counted at the end of the divider
+	var hint string
+	if first > 0 {
+		hint = fmt.Sprintf("◀ %d", first)
+	}
+	if end < len(allColumns) {
+		hint = strings.TrimSpace(fmt.Sprintf("%s  %d more ▶", hint, len(allColumns)-end))
+	}
+	divider := min(len(header)+10, m.width-2)
+	if hint != "" {
+		divider = max(divider-utf8.RuneCountInString(hint)-1, 0)
+		hint = " " + hint
+	}
+	b.WriteString(ui.MutedStyle.Render(strings.Repeat("─", divider) + hint))
 	b.WriteString("\n")
 
 	// Rows
@@ -1546,13 +1972,18 @@ func (m *ResultsModel) renderTableView(height int) string {
 		row := m.filteredTableRows[i]
 		isSelected := i == m.tableSelectedIdx
 
+		agentName := row.AgentName
+		if _, ok := m.markedAgents[row.AgentID]; ok {
+			agentName = ui.MarkedAgent + " " + agentName
+		}
+
 		var rowParts []string
-		rowParts = append(rowParts, fmt.Sprintf("%-*s", colWidths["_agent"], truncateString(row.AgentName, colWidths["_agent"])))
+		rowParts = append(rowParts, fmt.Sprintf("%-*s", colWidths["_agent"], truncateString(agentName, colWidths["_agent"])))
 
-		for _, col := range m.tableColumns {
-			width := colWidths[col]
-			value := row.Data[col]
-			rowParts = append(rowParts, fmt.Sprintf("%-*s", width, truncateString(value, width)))
+		// Nulls are dimmed only in plain rows, so the row style is not interrupted
+		plain := !isSelected && !row.HasError
+		for _, col := range columns {
+			rowParts = append(rowParts, m.renderCell(row.Data[col], col, colWidths[col], plain))
 		}
 
 		rowStr := strings.Join(rowParts, " │ ")
@@ -1570,6 +2001,86 @@ func (m *ResultsModel) renderTableView(height int) string {
 	return b.String()
 }
 
+// renderColumnPicker renders the column picker: visible columns are checked
+// and listed in table order
+func (m *ResultsModel) renderColumnPicker(height int) string {
+	var b strings.Builder
+	shown := 0
+	for _, col := range m.columnPicker {
+		if col.Visible {
+			shown++
+		}
+	}
+	switch m.pickerFor {
+	case pickGroupColumns:
+		b.WriteString(ui.HeaderStyle.Render("Group by"))
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  (%d of %d columns)", shown, len(m.columnPicker))))
+	case pickStackColumns:
+		b.WriteString(ui.HeaderStyle.Render("Stack by"))
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  (%d of %d columns)", shown, len(m.columnPicker))))
+	default:
+		b.WriteString(ui.HeaderStyle.Render("Columns"))
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  (%d of %d shown)", shown, len(m.columnPicker))))
+	}
+	b.WriteString("\n")
+
+	// Scroll to keep the cursor visible; the box border takes two lines
+	lines := max(height-5, 1)
+	start := 0
+	if m.columnPickerIdx >= lines {
+		start = m.columnPickerIdx - lines + 1
+	}
+	end := min(start+lines, len(m.columnPicker))
+
+	var list []string
+	for i := start; i < end; i++ {
+		col := m.columnPicker[i]
+		check := "[ ]"
+		if col.Visible {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, col.Name)
+		if i == m.columnPickerIdx {
+			line = ui.SelectedStyle.Render(line)
+		} else if !col.Visible {
+			line = ui.MutedStyle.Render(line)
+		}
+		list = append(list, line)
+	}
+	if len(list) == 0 {
+		list = append(list, ui.MutedStyle.Render("No columns yet"))
+	}
+	b.WriteString(ui.BoxStyle.Render(strings.Join(list, "\n")))
+	return b.String()
+}
+
+// renderCell pads a value to the column width: numbers are right-aligned
+// when the whole column is numeric, and nulls are dimmed when styled is set
+func (m *ResultsModel) renderCell(value resultset.Value, col string, width int, styled bool) string {
+	text := truncateString(formatCell(value), width)
+	if m.columnKinds[col] == resultset.KindNumber {
+		text = fmt.Sprintf("%*s", width, text)
+	} else {
+		text = fmt.Sprintf("%-*s", width, text)
+	}
+	if styled && value.IsNull() {
+		return ui.MutedStyle.Render(text)
+	}
+	return text
+}
+
+// formatCell returns the display text of a value: times in the local time
+// zone and JSON nulls as "null"
+func formatCell(value resultset.Value) string {
+	switch {
+	case value.Kind == resultset.KindTime:
+		return value.Time().Local().Format("2006-01-02 15:04:05")
+	case value.IsNull():
+		return "null"
+	}
+	return value.String()
+}
+
 // calculateColumnWidths determines optimal column widths
 func (m *ResultsModel) calculateColumnWidths() map[string]int {
 	widths := make(map[string]int)
@@ -1577,9 +2088,11 @@ func (m *ResultsModel) calculateColumnWidths() map[string]int {
 	// Agent column
 	widths["_agent"] = 15
 
-	// Start with header widths
-	for _, col := range m.tableColumns {
-		widths[col] = len(col)
+	columns := m.visibleColumns()
+
+	// Start with header widths, including any sort indicator
+	for _, col := range columns {
+		widths[col] = len(col) + utf8.RuneCountInString(m.sortIndicator(col))
 	}
 
 	// Check data widths (sample first 50 rows)
@@ -1590,40 +2103,23 @@ func (m *ResultsModel) calculateColumnWidths() map[string]int {
 			widths["_agent"] = min(len(row.AgentName), 20)
 		}
 		for col, value := range row.Data {
-			if len(value) > widths[col] {
-				widths[col] = len(value)
+			if text := formatCell(value); len(text) > widths[col] {
+				widths[col] = len(text)
 			}
 		}
 	}
 
-	// Cap column widths and distribute available space
-	totalWidth := widths["_agent"] + 3 // Agent + separator
-	for _, col := range m.tableColumns {
-		totalWidth += widths[col] + 3 // Column + separator
-	}
-
-	// If total is too wide, scale down
-	availableWidth := m.width - 4
-	if totalWidth > availableWidth && len(m.tableColumns) > 0 {
-		// Distribute space more evenly
-		perColWidth := (availableWidth - widths["_agent"] - 3) / len(m.tableColumns) - 3
-		if perColWidth < 8 {
-			perColWidth = 8
-		}
-		for _, col := range m.tableColumns {
-			if widths[col] > perColWidth {
-				widths[col] = perColWidth
-			}
-		}
-	}
+	// Wide tables are scrolled horizontally rather than squeezed, but a
+	// single column never needs scrolling itself
+	maxWidth := min(40, m.width-widths["_agent"]-7)
 
 	// Ensure minimum widths
 	for col := range widths {
 		if widths[col] < 5 {
 			widths[col] = 5
 		}
-		if widths[col] > 40 {
-			widths[col] = 40
+		if widths[col] > maxWidth {
+			widths[col] = max(maxWidth, 5)
 		}
 	}
 
@@ -1705,6 +2201,28 @@ func (m *ResultsModel) renderDetailView() string {
 func (m *ResultsModel) renderSearchBar() string {
 	var b strings.Builder
 
+	// Rows of one agent are shown before the search
+	if m.drillAgent != nil && m.viewMode == TableView {
+		b.WriteString(ui.HeaderStyle.Render("Agent: "))
+		b.WriteString(m.drillAgent.Name)
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  [%d rows] (Esc to go back)", len(m.filteredTableRows))))
+		if !m.searchMode && m.searchQuery == "" {
+			return b.String()
+		}
+		b.WriteString("  ")
+	}
+
+	// Rows of one group are shown before the search
+	if m.drillGroup != nil && m.viewMode == TableView {
+		b.WriteString(ui.HeaderStyle.Render("Group: "))
+		b.WriteString(m.groupLabel(m.drillGroup))
+		b.WriteString(ui.MutedStyle.Render(fmt.Sprintf("  [%d rows] (Esc to go back)", len(m.filteredTableRows))))
+		if !m.searchMode && m.searchQuery == "" {
+			return b.String()
+		}
+		b.WriteString("  ")
+	}
+
 	// Search label
 	if m.searchMode {
 		b.WriteString(ui.HeaderStyle.Render("Search: "))
@@ -1719,8 +2237,11 @@ func (m *ResultsModel) renderSearchBar() string {
 		b.WriteString(m.searchQuery)
 	}
 
-	// Match count
-	if m.searchQuery != "" {
+	// Syntax errors replace the match count, which is for the last valid query
+	var queryErr *resultset.QueryError
+	if errors.As(m.searchErr, &queryErr) {
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("  ✗ %s at %d", queryErr.Msg, queryErr.Pos+1)))
+	} else if m.searchQuery != "" {
 		totalCount := len(m.tableRows)
 		if m.viewMode == TreeView || m.viewMode == AggregatedView {
 			// For tree views, show matching nodes
@@ -1734,7 +2255,7 @@ func (m *ResultsModel) renderSearchBar() string {
 
 	// Help text when in search mode
 	if m.searchMode {
-		b.WriteString(ui.MutedStyle.Render("  (Enter to apply, Esc to clear)"))
+		b.WriteString(ui.MutedStyle.Render("  (Enter to apply, start with " + resultset.QueryPrefix + " for a query, ↑↓ history, Esc to clear)"))
 	} else if m.searchQuery != "" {
 		b.WriteString(ui.MutedStyle.Render("  (/ to edit, Esc to clear)"))
 	}
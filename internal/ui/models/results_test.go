@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/api/fake"
+	"github.com/Binmave/binmave-cli/internal/resultset"
+)
+
+func TestAppendTableRowsKeepsSelectionWhenSorted(t *testing.T) {
+	m := NewResultsModel("exec", fake.New())
+	m.sortKeys = []resultset.SortKey{{Column: "name"}}
+
+	m.appendTableRows([]api.ExecutionResult{
+		{AgentID: "agent-b", AgentName: "B", AnswerJSON: `{"name":"b"}`},
+		{AgentID: "agent-d", AgentName: "D", AnswerJSON: `{"name":"d"}`},
+	})
+	m.selectTableRow("agent-d", 0)
+	if m.tableSelectedIdx != 1 {
+		t.Fatalf("selected index = %d, want 1", m.tableSelectedIdx)
+	}
+
+	// A refresh brings a row that sorts above the selection
+	m.appendTableRows([]api.ExecutionResult{
+		{AgentID: "agent-a", AgentName: "A", AnswerJSON: `{"name":"a"}`},
+	})
+	if got := m.filteredTableRows[m.tableSelectedIdx].AgentID; got != "agent-d" {
+		t.Errorf("selected row is %s after refresh, want agent-d", got)
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Binmave/binmave-cli/internal/api"
+	"github.com/Binmave/binmave-cli/internal/ui"
+)
+
+// cacheSource is implemented by backends that can serve executions from the local cache
+type cacheSource interface {
+	CachedAt(executionID string) (time.Time, bool)
+	Offline() bool
+}
+
+// sourceBanner describes where the executions' data comes from, or "" when it
+// is live. Offline data is flagged as possibly stale.
+func sourceBanner(client api.Backend, executionIDs ...string) string {
+	source, ok := client.(cacheSource)
+	if !ok {
+		return ""
+	}
+
+	var cached []string
+	for _, id := range executionIDs {
+		if at, ok := source.CachedAt(id); ok {
+			cached = append(cached, at.Local().Format("2006-01-02 15:04"))
+		}
+	}
+
+	if source.Offline() {
+		if len(cached) == 0 {
+			return ui.WarningStyle.Render("⚠ OFFLINE")
+		}
+		return ui.WarningStyle.Render(fmt.Sprintf("⚠ OFFLINE - cached %s, may be stale", strings.Join(cached, ", ")))
+	}
+	if len(cached) > 0 {
+		return ui.MutedStyle.Render("Cached " + strings.Join(cached, ", "))
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Binmave/binmave-cli/internal/resultset"
+	"github.com/Binmave/binmave-cli/internal/ui/components"
+)
+
+// SetStackColumns sets the columns whose values the aggregated view stacks;
+// nil merges tree paths instead
+func (m *ResultsModel) SetStackColumns(columns []string) {
+	m.stackColumns = columns
+}
+
+// SetAnomalyThreshold sets the agent count at or below which aggregated
+// nodes are anomalies
+func (m *ResultsModel) SetAnomalyThreshold(threshold resultset.Threshold) {
+	m.anomalyThreshold = threshold
+}
+
+// SetRarestFirst orders the aggregated view least common first
+func (m *ResultsModel) SetRarestFirst(rarestFirst bool) {
+	m.rarestFirst = rarestFirst
+}
+
+// stacking reports whether the aggregated view stacks column values rather
+// than merging tree paths; flat rows can only be stacked
+func (m *ResultsModel) stacking() bool {
+	return len(m.stackColumns) > 0 || !m.isTreeData
+}
+
+// refreshAggregatedTree rebuilds the aggregated view, applying the search
+func (m *ResultsModel) refreshAggregatedTree() {
+	if m.searchQuery != "" {
+		m.filterTreeView()
+	} else {
+		m.rebuildAggregatedTree()
+	}
+}
+
+// rebuildStackTree builds the aggregated view as one node per combination of
+// values of the stacked columns, counting the agents whose rows matching the
+// search have it
+func (m *ResultsModel) rebuildStackTree() {
+	if len(m.stackColumns) == 0 {
+		// Start with the column focused in the table view
+		columns := m.visibleColumns()
+		if len(columns) == 0 {
+			m.aggregateTree = nil
+			m.treeView.SetAgents(nil)
+			return
+		}
+		m.stackColumns = []string{columns[m.focusedColumnIndex()]}
+	}
+
+	agents := make(map[string]bool)
+	stacker := resultset.NewStacker(m.stackColumns)
+	for _, row := range m.tableRows {
+		agents[row.AgentID] = true
+		// Agents with an empty answer count towards the total only
+		if len(row.Data) > 0 && m.searchFilter.Match(row.AgentName, row.Data) {
+			stacker.Add(row.AgentID, row.AgentName, row.Data)
+		}
+	}
+	totalAgents := len(agents)
+
+	m.aggregateTree = buildAggregatedNodes(stacker.Stacks(m.rarestFirst), totalAgents, m.anomalyThreshold.Limit(totalAgents), m.showAnomaliesOnly)
+	m.treeView.SetAgents([]*components.AgentTree{{
+		AgentID:   "aggregated",
+		AgentName: fmt.Sprintf("All Agents (%d) by %s", totalAgents, strings.Join(m.stackColumns, ", ")),
+		Roots:     m.aggregateTree,
+		NodeCount: stacker.Len(),
+		Expanded:  true,
+	}})
+}
@@ -37,6 +37,12 @@ var (
 			Bold(true).
 			Foreground(Primary)
 
+	// Focused table column header
+	FocusedHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Underline(true).
+				Foreground(Primary)
+
 	// Muted text
 	MutedStyle = lipgloss.NewStyle().
 			Foreground(Muted)
@@ -161,3 +167,6 @@ const (
 	TreeExpanded   = "▼"
 	TreeCollapsed  = "▶"
 )
+
+// MarkedAgent marks agents picked for a follow-up execution
+const MarkedAgent = "●"